@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -60,6 +61,175 @@ func BenchmarkScanner(b *testing.B) {
 	}
 }
 
+func BenchmarkScannerLongStrings(b *testing.B) {
+	tests := map[string]string{
+		"ascii":   strings.Repeat("lorem ipsum dolor sit amet ", 40),
+		"escaped": strings.Repeat(`lorem ipsum \"dolor\" sit amet\n`, 40),
+		"unicode": strings.Repeat("lorem ipsum dölör sït ämet ", 40),
+	}
+	for name, str := range tests {
+		var sb strings.Builder
+		sb.WriteByte('[')
+		for i := 0; i < 1000; i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(`{"id": 1, "text": "` + str + `"}`)
+		}
+		sb.WriteByte(']')
+		data := []byte(sb.String())
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sc := NewScannerBytes(data)
+				for len(sc.Next()) > 0 {
+				}
+				if err := sc.Error(); err != io.EOF {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// countingReader counts the reads made of an io.Reader.
+type countingReader struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *countingReader) Read(buf []byte) (int, error) {
+	c.reads++
+	return c.r.Read(buf)
+}
+
+func BenchmarkScannerSize(b *testing.B) {
+	// ~10MB of concatenated documents.
+	doc, err := io.ReadAll(fixture(b, "citm_catalog"))
+	check(b, err)
+	data := bytes.Repeat(doc, 6)
+	r := bytes.NewReader(data)
+	for _, size := range []int{0, 64 << 10, 1 << 20} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			reads := 0
+			for i := 0; i < b.N; i++ {
+				r.Seek(0, 0)
+				cr := &countingReader{r: r}
+				sc := NewScannerSize(cr, size)
+				for len(sc.Next()) > 0 {
+				}
+				reads += cr.reads
+			}
+			b.ReportMetric(float64(reads)/float64(b.N), "reads/op")
+		})
+	}
+}
+
+func BenchmarkScannerBytes(b *testing.B) {
+	for _, tc := range inputs {
+		r := fixture(b, tc.path)
+		data, err := io.ReadAll(r)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(tc.path, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sc := NewScannerBytes(data)
+				n := 0
+				for len(sc.Next()) > 0 {
+					n++
+				}
+				if n != tc.alltokens {
+					b.Fatalf("expected %v tokens, got %v", tc.alltokens, n)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkScannerReset(b *testing.B) {
+	const doc = `{"id": 12345, "name": "example", "tags": ["a", "b", "c"], "ok": true}`
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		r := strings.NewReader(doc)
+		for i := 0; i < b.N; i++ {
+			r.Reset(doc)
+			sc := NewScanner(r)
+			for len(sc.Next()) > 0 {
+			}
+		}
+	})
+	b.Run("reset", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		r := strings.NewReader(doc)
+		sc := NewScanner(r)
+		for i := 0; i < b.N; i++ {
+			r.Reset(doc)
+			sc.Reset(r)
+			for len(sc.Next()) > 0 {
+			}
+		}
+	})
+}
+
+func BenchmarkScannerKeys(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`[`)
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, `{"id": %d, "name": "n%d", "enabled": true, "tags": [1, 2], "owner": {"uid": %d}}, `, i, i, i)
+	}
+	sb.WriteString(`{}]`)
+	doc := []byte(sb.String())
+	b.ReportAllocs()
+	b.SetBytes(int64(len(doc)))
+	for i := 0; i < b.N; i++ {
+		sc := NewScannerBytes(doc)
+		for len(sc.Next()) > 0 {
+		}
+	}
+}
+
+func BenchmarkScannerPool(b *testing.B) {
+	const doc = `{"id": 12345, "name": "example", "tags": ["a", "b", "c"], "ok": true}`
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		b.RunParallel(func(pb *testing.PB) {
+			r := strings.NewReader(doc)
+			for pb.Next() {
+				r.Reset(doc)
+				sc := NewScanner(r)
+				for len(sc.Next()) > 0 {
+				}
+			}
+		})
+	})
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(doc)))
+		b.RunParallel(func(pb *testing.PB) {
+			r := strings.NewReader(doc)
+			for pb.Next() {
+				r.Reset(doc)
+				sc := GetScanner(r)
+				for len(sc.Next()) > 0 {
+				}
+				PutScanner(sc)
+			}
+		})
+	})
+}
+
 func BenchmarkBufferSize(b *testing.B) {
 	b.Skip()
 	sizes := []int{16, 64, 256, 512, 1 << 10, 2 << 10, 4 << 10, 8 << 10, 16 << 10, 64 << 10, 1 << 20}
@@ -249,6 +419,36 @@ func BenchmarkDecoderNextToken(b *testing.B) {
 	}
 }
 
+func BenchmarkDecoderInternKeys(b *testing.B) {
+	// 100k rows of 5 keys each.
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < 100000; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `{"id": %d, "name": "row %d", "active": true, "score": 1.5, "created_at": "2020-01-01"}`, i, i)
+	}
+	sb.WriteByte(']')
+	r := strings.NewReader(sb.String())
+	var buf [8 << 10]byte
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("intern=%v", intern), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(r.Size())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.Seek(0, 0)
+				dec := NewDecoderBuffer(r, buf[:])
+				dec.SetInternKeys(intern)
+				var rows []map[string]interface{}
+				err := dec.Decode(&rows)
+				check(b, err)
+			}
+		})
+	}
+}
+
 // fuxture returns a *bytes.Reader for the contents of path.
 func fixture(tb testing.TB, path string) *bytes.Reader {
 	f, err := os.Open(filepath.Join("testdata", path+".json.gz"))
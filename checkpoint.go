@@ -0,0 +1,108 @@
+package json
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// A Checkpoint records the state of a Scanner between two tokens, so that it
+// may later be returned to that point with Rollback, see Scanner.Checkpoint.
+type Checkpoint struct {
+	pos        int64 // stream offset of the end of the last token
+	depth      int
+	containers stack
+	keys       [][]byte
+	keyNext    bool
+	eofSent    bool
+	ended      bool
+	valid      validator
+	err        error
+}
+
+// Checkpoint records the scanner's position following the token most
+// recently returned by Next, for speculative parsing: should the tokens which
+// follow turn out not to be what the caller hoped, Rollback returns the
+// scanner to the checkpoint, so that they can be scanned again.
+//
+// The scanner retains its input from the most recent checkpoint on, growing
+// its buffer as necessary, so that any number of rollbacks to that checkpoint
+// succeed; the input is released once a later checkpoint is taken, or Commit
+// is called. Rollback to an earlier checkpoint succeeds only while the input
+// from it on happens to remain in the buffer.
+func (s *Scanner) Checkpoint() Checkpoint {
+	ended := s.ended
+	if s.offset > 0 {
+		ended = s.DocumentEnd()
+	}
+	// the current key must outlast its place in the buffer.
+	s.saveKey()
+	cp := Checkpoint{
+		pos:        s.br.pos() + int64(s.offset),
+		depth:      s.depth,
+		containers: slices.Clone(s.containers),
+		keys:       cloneKeys(s.keys[:s.containers.len()]),
+		keyNext:    s.keyNext,
+		eofSent:    s.eofSent,
+		ended:      ended,
+		valid:      s.valid.clone(),
+		err:        s.err,
+	}
+	s.br.pin, s.br.pinned = cp.pos, true
+	return cp
+}
+
+// Rollback returns the scanner to the checkpoint cp, taken with Checkpoint,
+// so that the next call to Next returns the token which followed it, along
+// with the state of the structural validator, the nesting depth, and the
+// current key. Errors recorded since the checkpoint are discarded, although
+// the underlying io.Reader is not read again. Rollback returns an error if
+// the input from cp on has been released from the buffer.
+func (s *Scanner) Rollback(cp Checkpoint) error {
+	start, end := s.br.base, s.br.base+int64(len(s.br.data))
+	if cp.pos < start || cp.pos > end {
+		return fmt.Errorf("Rollback: the input at offset %d is no longer buffered", cp.pos)
+	}
+	s.br.offset = int(cp.pos - start)
+	s.offset = 0
+	s.depth = cp.depth
+	s.containers = append(s.containers[:0], cp.containers...)
+	s.keys = cloneKeys(cp.keys)
+	s.keyLen, s.br.held = 0, false
+	s.keyNext = cp.keyNext
+	s.eofSent = cp.eofSent
+	s.ended = cp.ended
+	s.valid = cp.valid.clone()
+	s.err = cp.err
+	return nil
+}
+
+// Commit discards the most recent checkpoint, once the speculative parse it
+// guarded has succeeded, so that the scanner no longer retains its input
+// from the checkpoint on. A later Rollback to it, or to any earlier
+// checkpoint, succeeds only while the input happens to remain in the buffer.
+func (s *Scanner) Commit() {
+	s.br.pin, s.br.pinned = 0, false
+}
+
+// cloneKeys returns a copy of the keys of the open containers, see
+// Scanner.CurrentKey.
+func cloneKeys(keys [][]byte) [][]byte {
+	c := make([][]byte, len(keys))
+	for i, key := range keys {
+		c[i] = slices.Clone(key)
+	}
+	return c
+}
+
+// clone returns a copy of v which shares none of its state.
+func (v *validator) clone() validator {
+	c := *v
+	c.stack = slices.Clone(v.stack)
+	c.path = slices.Clone(v.path)
+	c.keys = slices.Clone(v.keys)
+	for i, keys := range c.keys {
+		c.keys[i] = maps.Clone(keys)
+	}
+	return c
+}
@@ -0,0 +1,113 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScannerRollback(t *testing.T) {
+	in := `{"a": [1, 2, 3], "b": true}`
+	sc := NewScanner(&SmallReader{r: strings.NewReader(in)})
+	sc.SetValidate(true)
+	sc.SetTrackPath(true)
+	for _, want := range []string{`{`, `"a"`, `:`} {
+		if got := sc.Next(); string(got) != want {
+			t.Fatalf("expected: %q, got: %q", want, got)
+		}
+	}
+	cp := sc.Checkpoint()
+	for i := 0; i < 3; i++ {
+		// speculatively scan some of the value, then go back.
+		for j := 0; j < 4; j++ {
+			sc.Next()
+		}
+		check(t, sc.Rollback(cp))
+		if got, want := sc.Path(), "/a"; got != want {
+			t.Fatalf("expected: path %q, got: %q", want, got)
+		}
+		if got := sc.CurrentKey(); string(got) != "a" {
+			t.Fatalf("expected: key %q, got: %q", "a", got)
+		}
+	}
+	got, _ := scanAll(sc)
+	if want := `[[ 1 , 2 , 3 ] , "b" : true }]`; fmt.Sprint(got) != want {
+		t.Fatalf("expected: %s, got: %s", want, got)
+	}
+	if err := sc.validEnd(); err != nil {
+		t.Fatalf("expected: a valid document, got: %v", err)
+	}
+
+	// errors recorded after the checkpoint are discarded.
+	sc = NewScanner(strings.NewReader(`[1, 2] 3`))
+	sc.SetValidate(true)
+	sc.SetAllowedTopLevel(KindArrayStart)
+	sc.Next()
+	cp = sc.Checkpoint()
+	scanAll(sc)
+	if sc.Error() == nil {
+		t.Fatalf("expected: an error")
+	}
+	check(t, sc.Rollback(cp))
+	if got := sc.Next(); string(got) != "1" {
+		t.Fatalf("expected: %q, got: %q", "1", got)
+	}
+}
+
+func TestScannerRollbackLarge(t *testing.T) {
+	// the input from the checkpoint on is retained however long it is.
+	in := "[" + strings.Repeat(`"abcdefghijklmnopqrstuvwxyz", `, 10000) + "1]"
+	sc := NewScanner(iotest.HalfReader(strings.NewReader(in)))
+	sc.Next()
+	cp := sc.Checkpoint()
+	first, err := scanAll(sc)
+	if err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+	check(t, sc.Rollback(cp))
+	second, _ := scanAll(sc)
+	if len(first) != 20002 || fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Fatalf("expected: the same %d tokens, got: %d and %d", 20002, len(first), len(second))
+	}
+
+	// but that from an earlier checkpoint need not be.
+	sc = NewScanner(strings.NewReader(in))
+	sc.Next()
+	early := sc.Checkpoint()
+	for i := 0; i < 10; i++ {
+		sc.Next()
+	}
+	late := sc.Checkpoint()
+	tok := string(sc.Next())
+	for i := 0; i < 10000; i++ {
+		sc.Next()
+	}
+	if err := sc.Rollback(early); err == nil {
+		t.Fatalf("expected: an error rolling back to a released checkpoint")
+	}
+	check(t, sc.Rollback(late))
+	if got := string(sc.Next()); got != tok {
+		t.Fatalf("expected: %q, got: %q", tok, got)
+	}
+}
+
+func TestScannerCommit(t *testing.T) {
+	in := "[" + strings.Repeat(`"abcdefghijklmnopqrstuvwxyz", `, 10000) + "1]"
+	sc := NewScanner(strings.NewReader(in))
+	sc.Next()
+	sc.Checkpoint()
+	for i := 0; i < 10; i++ {
+		sc.Next()
+	}
+	sc.Commit()
+	got, err := scanAll(sc)
+	if err != io.EOF || len(got) != 20002-10 {
+		t.Fatalf("expected: %d tokens, got: %d, %v", 20002-10, len(got), err)
+	}
+	// once committed, the input is no longer retained.
+	if n := cap(sc.br.data); n > 2*newBufferSize {
+		t.Fatalf("expected: a bounded buffer, got: %d bytes", n)
+	}
+}
@@ -0,0 +1,86 @@
+package json
+
+// InObject reports whether the innermost container open at the token most
+// recently returned by Next is an object; that is, whether the token is a key,
+// a member value, or punctuation within an object. An object's own opening
+// brace is within it, its closing brace is not.
+func (s *Scanner) InObject() bool {
+	n := s.containers.len()
+	return n > 0 && s.containers[n-1]
+}
+
+// InArray reports whether the innermost container open at the token most
+// recently returned by Next is an array, as InObject does for objects. At the
+// top level, outside any container, neither InObject nor InArray is true.
+func (s *Scanner) InArray() bool {
+	n := s.containers.len()
+	return n > 0 && !s.containers[n-1]
+}
+
+// CurrentKey returns the key of the member of the innermost open object
+// which the token most recently returned by Next belongs to, excluding the
+// surrounding quotes, with any escape sequences left as is. Once a member's
+// value is itself an object or array, its key is again current after the
+// nested value closes. CurrentKey returns nil if the innermost container is
+// not an object, or no key has yet been read in it. The key is only valid
+// until the next call to Next.
+func (s *Scanner) CurrentKey() []byte {
+	if !s.InObject() {
+		return nil
+	}
+	key := s.keys[s.containers.len()-1]
+	if s.keyLen > 0 {
+		start := int(s.keyPos - s.br.base)
+		key = s.br.data[start : start+s.keyLen]
+	}
+	if len(key) == 0 {
+		return nil
+	}
+	return key[1 : len(key)-1]
+}
+
+// open records the opening of an object, if obj is true, or an array.
+func (s *Scanner) open(obj bool) {
+	s.saveKey()
+	s.containers.push(obj)
+	n := s.containers.len()
+	if len(s.keys) < n {
+		s.keys = append(s.keys, nil)
+	}
+	s.keys[n-1] = s.keys[n-1][:0]
+	s.keyNext = obj
+}
+
+// close records the closing of the innermost open object or array.
+func (s *Scanner) close() {
+	s.containers.pop()
+	s.keyLen, s.br.held = 0, false
+}
+
+// setKey records the string token of length n at the start of the window as
+// the current key of the innermost open object. Rather than copy the key,
+// which most callers never ask for, the scanner notes where it is, and keeps
+// it in the buffer until there is a new current key. The quotes are kept, so
+// that an empty key can be told from none.
+func (s *Scanner) setKey(n int) {
+	s.keyPos, s.keyLen = s.br.pos(), n
+	s.br.hold, s.br.held = s.keyPos, true
+}
+
+// copyKey records a copy of the string token key as the current key of the
+// innermost open object.
+func (s *Scanner) copyKey(key []byte) {
+	n := s.containers.len()
+	s.keys[n-1] = append(s.keys[n-1][:0], key...)
+	s.keyLen, s.br.held = 0, false
+}
+
+// saveKey copies the current key of the innermost open object out of the
+// buffer, once it must outlast a nested object or array, which may be of
+// any length.
+func (s *Scanner) saveKey() {
+	if s.keyLen > 0 {
+		start := int(s.keyPos - s.br.base)
+		s.copyKey(s.br.data[start : start+s.keyLen])
+	}
+}
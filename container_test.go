@@ -0,0 +1,88 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScannerContainer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // the context of each token: - top level, [ array, or {key object
+	}{
+		{in: `1`, want: `[1 -]`},
+		{in: `"a" "b"`, want: `["a" - "b" -]`},
+		{in: `[]`, want: `[[ [ ] -]`},
+		{in: `{}`, want: `[{ { } -]`},
+		{in: `[1, "a"]`, want: `[[ [ 1 [ , [ "a" [ ] -]`},
+		{
+			in:   `{"a": 1, "b": "c"}`,
+			want: `[{ { "a" {a : {a 1 {a , {a "b" {b : {b "c" {b } -]`,
+		},
+		{
+			in:   `{"a": {"b": 1}, "c": [1, {"d": 2}]}`,
+			want: `[{ { "a" {a : {a { { "b" {b : {b 1 {b } {a , {a "c" {c : {c [ [ 1 [ , [ { { "d" {d : {d 2 {d } [ ] {c } -]`,
+		},
+		{in: `{"": [], "\n": 1}`, want: `[{ { "" { : { [ [ ] { , { "\n" {\n : {\n 1 {\n } -]`},
+		{in: `{"a": 1} {"b": 2}`, want: `[{ { "a" {a : {a 1 {a } - { { "b" {b : {b 2 {b } -]`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			var got []string
+			for tok := sc.Next(); len(tok) > 0; tok = sc.Next() {
+				ctx := "-"
+				switch {
+				case sc.InObject():
+					ctx = "{" + string(sc.CurrentKey())
+				case sc.InArray():
+					ctx = "["
+				}
+				got = append(got, string(tok), ctx)
+			}
+			if fmt.Sprint(got) != tc.want {
+				t.Fatalf("expected: %s, got: %s", tc.want, got)
+			}
+		})
+	}
+
+	// keys stay current across reads, without the input being retained.
+	var b strings.Builder
+	b.WriteString(`{`)
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&b, `"k%d": %d, "o%d": {"n": [%d]}, `, i, i, i, i)
+	}
+	b.WriteString(`"end": null}`)
+	sc := NewScanner(iotest.HalfReader(strings.NewReader(b.String())))
+	for tok := sc.Next(); len(tok) > 0; tok = sc.Next() {
+		if kinds[tok[0]] != KindNumber || !sc.InObject() {
+			continue
+		}
+		if got, want := string(sc.CurrentKey()), "k"+string(tok); got != want {
+			t.Fatalf("expected: key %q, got: %q", want, got)
+		}
+	}
+	if n := cap(sc.br.data); n > 2*newBufferSize {
+		t.Fatalf("expected: a bounded buffer, got: %d bytes", n)
+	}
+
+	// an empty key is told apart from none.
+	sc = NewScannerBytes([]byte(`{"": 1}`))
+	sc.Next()
+	if key := sc.CurrentKey(); key != nil {
+		t.Fatalf("expected: no key, got: %q", key)
+	}
+	sc.Next()
+	if key := sc.CurrentKey(); key == nil || len(key) != 0 {
+		t.Fatalf("expected: an empty key, got: %q", key)
+	}
+
+	// the context is discarded by Reset.
+	sc.Reset(strings.NewReader(`2`))
+	if sc.InObject() || sc.CurrentKey() != nil {
+		t.Fatalf("expected: no context after Reset")
+	}
+}
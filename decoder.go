@@ -2,18 +2,22 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"unsafe"
 )
 
 // A Decoder decodes JSON values from an input stream.
 type Decoder struct {
-	scanner Scanner
-	state   func(*Decoder) ([]byte, error)
+	scanner   Scanner
+	state     func(*Decoder) ([]byte, error)
+	useNumber bool // decode numbers into interface{} as json.Number
 	stack
 }
 
@@ -82,10 +86,24 @@ func (d *Decoder) Token() (json.Token, error) {
 	case '"':
 		return string(tok[1 : len(tok)-1]), nil
 	default:
-		return strconv.ParseFloat(bytesToString(tok), 64)
+		return d.numberAny(tok)
 	}
 }
 
+// SetUseNumber controls whether numbers decoded into an interface{}, and
+// those returned by Token, are represented as a json.Number holding the
+// number exactly as it appears in the input, rather than as a float64, which
+// cannot represent all integers and decimals precisely.
+func (d *Decoder) SetUseNumber(v bool) {
+	d.useNumber = v
+}
+
+// SetAllowUnderscores controls whether numbers may contain underscores as
+// digit group separators. See Scanner.SetAllowUnderscores.
+func (d *Decoder) SetAllowUnderscores(v bool) {
+	d.scanner.SetAllowUnderscores(v)
+}
+
 // NextToken returns a []byte referencing the next logical token in the stream.
 // The []byte is valid until Token is called again.
 // At the end of the input stream, Token returns nil, io.EOF.
@@ -114,7 +132,7 @@ func (d *Decoder) NextToken() ([]byte, error) {
 func (d *Decoder) stateObjectString() ([]byte, error) {
 	tok := d.scanner.Next()
 	if len(tok) < 1 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, d.unexpectedEnd("stateObjectString", "string key or object end")
 	}
 	switch tok[0] {
 	case '}':
@@ -131,29 +149,31 @@ func (d *Decoder) stateObjectString() ([]byte, error) {
 	case '"':
 		d.state = (*Decoder).stateObjectColon
 		return tok, nil
+	case Colon:
+		return nil, d.unexpectedColon("stateObjectString")
 	default:
-		return nil, fmt.Errorf("stateObjectString: missing string key")
+		return nil, fmt.Errorf("stateObjectString: missing string key, got %s", describe(tok))
 	}
 }
 
 func (d *Decoder) stateObjectColon() ([]byte, error) {
 	tok := d.scanner.Next()
 	if len(tok) < 1 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, d.unexpectedEnd("stateObjectColon", "colon")
 	}
 	switch tok[0] {
 	case Colon:
 		d.state = (*Decoder).stateObjectValue
 		return d.NextToken()
 	default:
-		return tok, fmt.Errorf("stateObjectColon: expecting colon")
+		return tok, fmt.Errorf("stateObjectColon: expecting colon, got %s", describe(tok))
 	}
 }
 
 func (d *Decoder) stateObjectValue() ([]byte, error) {
 	tok := d.scanner.Next()
 	if len(tok) < 1 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, d.unexpectedEnd("stateObjectValue", "value")
 	}
 	switch tok[0] {
 	case '{':
@@ -164,6 +184,10 @@ func (d *Decoder) stateObjectValue() ([]byte, error) {
 		d.state = (*Decoder).stateArrayValue
 		d.push(false)
 		return tok, nil
+	case Colon:
+		return nil, d.unexpectedColon("stateObjectValue")
+	case Comma:
+		return nil, fmt.Errorf("stateObjectValue: unexpected comma")
 	default:
 		d.state = (*Decoder).stateObjectComma
 		return tok, nil
@@ -173,7 +197,7 @@ func (d *Decoder) stateObjectValue() ([]byte, error) {
 func (d *Decoder) stateObjectComma() ([]byte, error) {
 	tok := d.scanner.Next()
 	if len(tok) < 1 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, d.unexpectedEnd("stateObjectComma", "comma or object end")
 	}
 	switch tok[0] {
 	case '}':
@@ -190,15 +214,17 @@ func (d *Decoder) stateObjectComma() ([]byte, error) {
 	case Comma:
 		d.state = (*Decoder).stateObjectString
 		return d.NextToken()
+	case Colon:
+		return nil, d.unexpectedColon("stateObjectComma")
 	default:
-		return tok, fmt.Errorf("stateObjectComma: expecting comma")
+		return tok, fmt.Errorf("stateObjectComma: expecting comma, got %s", describe(tok))
 	}
 }
 
 func (d *Decoder) stateArrayValue() ([]byte, error) {
 	tok := d.scanner.Next()
 	if len(tok) < 1 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, d.unexpectedEnd("stateArrayValue", "value or array end")
 	}
 	switch tok[0] {
 	case '{':
@@ -220,6 +246,8 @@ func (d *Decoder) stateArrayValue() ([]byte, error) {
 			d.state = (*Decoder).stateArrayComma
 		}
 		return tok, nil
+	case Colon:
+		return nil, d.unexpectedColon("stateArrayValue")
 	case ',':
 		return nil, fmt.Errorf("stateArrayValue: unexpected comma")
 	default:
@@ -231,7 +259,7 @@ func (d *Decoder) stateArrayValue() ([]byte, error) {
 func (d *Decoder) stateArrayComma() ([]byte, error) {
 	tok := d.scanner.Next()
 	if len(tok) < 1 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, d.unexpectedEnd("stateArrayComma", "comma or array end")
 	}
 	switch tok[0] {
 	case ']':
@@ -248,15 +276,17 @@ func (d *Decoder) stateArrayComma() ([]byte, error) {
 	case Comma:
 		d.state = (*Decoder).stateArrayValue
 		return d.NextToken()
+	case Colon:
+		return nil, d.unexpectedColon("stateArrayComma")
 	default:
-		return nil, fmt.Errorf("stateArrayComma: expected comma, %v", d.stack)
+		return nil, fmt.Errorf("stateArrayComma: expected comma, got %s", describe(tok))
 	}
 }
 
 func (d *Decoder) stateValue() ([]byte, error) {
 	tok := d.scanner.Next()
 	if len(tok) < 1 {
-		return nil, io.ErrUnexpectedEOF
+		return nil, d.unexpectedEnd("stateValue", "value")
 	}
 	switch tok[0] {
 	case '{':
@@ -267,6 +297,8 @@ func (d *Decoder) stateValue() ([]byte, error) {
 		d.state = (*Decoder).stateArrayValue
 		d.push(false)
 		return tok, nil
+	case Colon:
+		return nil, d.unexpectedColon("stateValue")
 	case ',':
 		return nil, fmt.Errorf("stateValue: unexpected comma")
 	default:
@@ -277,6 +309,18 @@ func (d *Decoder) stateValue() ([]byte, error) {
 
 func (d *Decoder) stateEnd() ([]byte, error) { return nil, io.EOF }
 
+// unexpectedEnd returns the error for the stream ending, or holding a token
+// which could not be scanned, where state expected want.
+func (d *Decoder) unexpectedEnd(state, want string) error {
+	return fmt.Errorf("%s: expecting %s: %w", state, want, d.scanner.tokenErr(io.ErrUnexpectedEOF))
+}
+
+// unexpectedColon returns an ErrUnexpectedColon error for the colon most
+// recently returned by the scanner, reported by state.
+func (d *Decoder) unexpectedColon(state string) error {
+	return fmt.Errorf("%s: %w at offset %d", state, ErrUnexpectedColon, d.scanner.br.pos())
+}
+
 // Decode reads the next JSON-encoded value from its input and stores it
 // in the value pointed to by v.
 func (d *Decoder) Decode(v interface{}) error {
@@ -296,6 +340,18 @@ func (d *Decoder) decodeValue(v reflect.Value) error {
 	if err != nil {
 		return err
 	}
+	return d.decodeToken(v, tok)
+}
+
+// decodeToken stores the value starting with tok, the current token, in v,
+// consuming the rest of the value.
+func (d *Decoder) decodeToken(v reflect.Value, tok []byte) error {
+	if v.Kind() == reflect.Ptr && tok[0] != Null {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeToken(v.Elem(), tok)
+	}
 	switch tok[0] {
 	case '{':
 		switch v.Kind() {
@@ -310,6 +366,8 @@ func (d *Decoder) decodeValue(v reflect.Value) error {
 			v.Set(reflect.ValueOf(m))
 		case reflect.Map:
 			return d.decodeMap(v)
+		case reflect.Struct:
+			return d.decodeStruct(v)
 		default:
 			return fmt.Errorf("decodeValue: unhandled type: %v", v.Kind())
 		}
@@ -325,6 +383,10 @@ func (d *Decoder) decodeValue(v reflect.Value) error {
 				return err
 			}
 			v.Set(reflect.ValueOf(s))
+		case reflect.Slice:
+			return d.decodeSlice(v)
+		case reflect.Array:
+			return d.decodeArray(v)
 		default:
 			return fmt.Errorf("unhandled type: %v", v.Kind())
 		}
@@ -345,7 +407,7 @@ func (d *Decoder) decodeValue(v reflect.Value) error {
 		return nil
 	case Null:
 		switch v.Kind() {
-		case reflect.Ptr, reflect.Map, reflect.Slice:
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
 			v.Set(reflect.Zero(v.Type()))
 			return nil
 		default:
@@ -357,10 +419,16 @@ func (d *Decoder) decodeValue(v reflect.Value) error {
 			if v.NumMethod() > 0 {
 				return fmt.Errorf("cannot decode object into Go value of type %v", v.Type())
 			}
-			s := string(tok[1 : len(tok)-1])
+			s, err := Unescape(tok)
+			if err != nil {
+				return err
+			}
 			v.Set(reflect.ValueOf(s))
 		case reflect.String:
-			s := string(tok[1 : len(tok)-1])
+			s, err := Unescape(tok)
+			if err != nil {
+				return err
+			}
 			v.SetString(s)
 		default:
 			return fmt.Errorf("unhandled type: %v", v.Kind())
@@ -372,25 +440,25 @@ func (d *Decoder) decodeValue(v reflect.Value) error {
 			if v.NumMethod() > 0 {
 				return fmt.Errorf("cannot decode number into Go value of type %v", v.Type())
 			}
-			f, err := strconv.ParseFloat(bytesToString(tok), 64)
+			n, err := d.numberAny(tok)
 			if err != nil {
-				return fmt.Errorf("cannot convert %q to float: %v", tok, err)
+				return err
 			}
-			v.Set(reflect.ValueOf(f))
+			v.Set(reflect.ValueOf(n))
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			i, err := strconv.ParseInt(bytesToString(tok), 10, 64)
+			i, err := strconv.ParseInt(d.number(tok), 10, 64)
 			if err != nil || v.OverflowInt(i) {
 				return fmt.Errorf("cannot convert %q to int: %v", tok, err)
 			}
 			v.SetInt(i)
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			u, err := strconv.ParseUint(bytesToString(tok), 10, 64)
+			u, err := strconv.ParseUint(d.number(tok), 10, 64)
 			if err != nil || v.OverflowUint(u) {
 				return fmt.Errorf("cannot convert %q to uint: %v", tok, err)
 			}
 			v.SetUint(u)
 		case reflect.Float64, reflect.Float32:
-			f, err := strconv.ParseFloat(bytesToString(tok), v.Type().Bits())
+			f, err := ParseFloat(tok)
 			if err != nil || v.OverflowFloat(f) {
 				return fmt.Errorf("cannot convert %q to float: %v", tok, err)
 			}
@@ -400,7 +468,7 @@ func (d *Decoder) decodeValue(v reflect.Value) error {
 		}
 		return nil
 	default:
-		return fmt.Errorf("decodeValue: unhandled token: %c", tok[0])
+		return fmt.Errorf("decodeValue: unhandled token: %s", describe(tok))
 	}
 }
 
@@ -409,6 +477,12 @@ func (d *Decoder) decodeValueAny() (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	return d.decodeTokenAny(tok)
+}
+
+// decodeTokenAny returns the value starting with tok, the current token, as
+// an interface{}, consuming the rest of the value.
+func (d *Decoder) decodeTokenAny(tok []byte) (interface{}, error) {
 	switch tok[0] {
 	case '{':
 		return d.decodeMapAny()
@@ -417,14 +491,13 @@ func (d *Decoder) decodeValueAny() (interface{}, error) {
 	case True, False:
 		return tok[0] == 't', nil
 	case '"':
-		return string(tok[1 : len(tok)-1]), nil
+		return Unescape(tok)
 	case Null:
 		return nil, nil
 	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-		s := bytesToString(tok)
-		return strconv.ParseFloat(s, 64)
+		return d.numberAny(tok)
 	default:
-		return fmt.Errorf("decodeValueAny: unhandled token: %c", tok[0]), nil
+		return nil, fmt.Errorf("decodeValueAny: unhandled token: %s", describe(tok))
 	}
 }
 
@@ -439,7 +512,10 @@ func (d *Decoder) decodeMapAny() (map[string]interface{}, error) {
 			return m, nil
 		}
 
-		key := string(tok[1 : len(tok)-1])
+		key, err := d.scanner.KeyString(tok)
+		if err != nil {
+			return nil, err
+		}
 		val, err := d.decodeValueAny()
 		if err != nil {
 			return nil, fmt.Errorf("decodeMapAny: %w", err)
@@ -454,6 +530,9 @@ func (d *Decoder) decodeMap(v reflect.Value) error {
 	if kt.Kind() != reflect.String {
 		return fmt.Errorf("cannot decode object into map with key type %v", kt)
 	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
 
 	for {
 		tok, err := d.NextToken()
@@ -463,7 +542,10 @@ func (d *Decoder) decodeMap(v reflect.Value) error {
 		if tok[0] == '}' {
 			return nil
 		}
-		key := string(tok[1 : len(tok)-1])
+		key, err := d.scanner.KeyString(tok)
+		if err != nil {
+			return err
+		}
 		kv := reflect.ValueOf(key).Convert(kt)
 
 		value := reflect.New(t.Elem()).Elem()
@@ -474,6 +556,121 @@ func (d *Decoder) decodeMap(v reflect.Value) error {
 	}
 }
 
+// decodeStruct decodes the members of an object into the fields of the
+// struct v, matching keys to field names as encoding/json does. Members with
+// no matching field are skipped.
+func (d *Decoder) decodeStruct(v reflect.Value) error {
+	fields := cachedFields(v.Type())
+	for {
+		tok, err := d.NextToken()
+		if err != nil {
+			return err
+		}
+		if tok[0] == '}' {
+			return nil
+		}
+		key := bytesToString(tok[1 : len(tok)-1])
+		if bytes.IndexByte(tok, '\\') >= 0 {
+			if key, err = Unescape(tok); err != nil {
+				return err
+			}
+		}
+		f := fields.lookup(key)
+		if f == nil {
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeValue(v.FieldByIndex(f.index)); err != nil {
+			return fmt.Errorf("field %s.%s: %w", v.Type(), f.name, err)
+		}
+	}
+}
+
+// decodeSlice decodes the elements of an array into the slice v, replacing
+// its contents.
+func (d *Decoder) decodeSlice(v reflect.Value) error {
+	if v.IsNil() {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	v.SetLen(0)
+	for i := 0; ; i++ {
+		tok, err := d.NextToken()
+		if err != nil {
+			return err
+		}
+		if tok[0] == ']' {
+			return nil
+		}
+		if i == v.Cap() {
+			v.Grow(1)
+		}
+		v.SetLen(i + 1)
+		elem := v.Index(i)
+		elem.Set(reflect.Zero(elem.Type()))
+		if err := d.decodeToken(elem, tok); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeArray decodes the elements of an array into the Go array v. Excess
+// elements are skipped, missing elements are zeroed.
+func (d *Decoder) decodeArray(v reflect.Value) error {
+	for i := 0; ; i++ {
+		tok, err := d.NextToken()
+		if err != nil {
+			return err
+		}
+		if tok[0] == ']' {
+			for ; i < v.Len(); i++ {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+			return nil
+		}
+		if i >= v.Len() {
+			if err := d.skipToken(tok); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeToken(v.Index(i), tok); err != nil {
+			return err
+		}
+	}
+}
+
+// skipValue consumes the next value without decoding it.
+func (d *Decoder) skipValue() error {
+	tok, err := d.NextToken()
+	if err != nil {
+		return err
+	}
+	return d.skipToken(tok)
+}
+
+// skipToken consumes the rest of the value starting with tok, the current
+// token, without decoding it.
+func (d *Decoder) skipToken(tok []byte) error {
+	depth := 0
+	for {
+		switch tok[0] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+		var err error
+		if tok, err = d.NextToken(); err != nil {
+			return err
+		}
+	}
+}
+
 func (d *Decoder) decodeSliceAny() ([]interface{}, error) {
 	s := make([]interface{}, 0, 1)
 	for {
@@ -481,38 +678,114 @@ func (d *Decoder) decodeSliceAny() ([]interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-		switch tok[0] {
-		case ']':
+		if tok[0] == ']' {
 			return s, nil
-		case '{':
-			m, err := d.decodeMapAny()
-			if err != nil {
-				return nil, err
-			}
-			s = append(s, m)
-		case '[':
-			sv, err := d.decodeSliceAny()
-			if err != nil {
-				return nil, err
-			}
-			s = append(s, sv)
-		case True, False:
-			s = append(s, tok[0] == 't')
-		case '"':
-			s = append(s, string(tok[1:len(tok)-1]))
-		case Null:
-			s = append(s, nil)
-		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			ss := bytesToString(tok)
-			f, err := strconv.ParseFloat(ss, 64)
-			if err != nil {
-				return nil, fmt.Errorf("cannot convert %q to float: %v", tok, err)
-			}
-			s = append(s, f)
 		}
+		v, err := d.decodeTokenAny(tok)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+}
+
+// number returns the number token tok as a string for conversion by strconv.
+func (d *Decoder) number(tok []byte) string {
+	if d.scanner.underscores {
+		tok = withoutUnderscores(nil, tok)
+	}
+	return bytesToString(tok)
+}
+
+// numberAny returns the number token tok as a value for an interface{}: a
+// float64, or a json.Number if SetUseNumber is enabled.
+func (d *Decoder) numberAny(tok []byte) (interface{}, error) {
+	if d.useNumber {
+		// the number may reference the scanner's buffer, copy it.
+		return json.Number(strings.Clone(d.number(tok))), nil
 	}
+	f, err := ParseFloat(tok)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %q to float: %v", tok, err)
+	}
+	return f, nil
 }
 
 func bytesToString(b []byte) string {
 	return unsafe.String(unsafe.SliceData(b), len(b))
 }
+
+// A field is a struct field which may be decoded into.
+type field struct {
+	name  string // the key which selects the field
+	index []int  // for reflect.Value.FieldByIndex
+}
+
+// structFields holds the decodable fields of a struct type.
+type structFields struct {
+	list   []field
+	byName map[string]*field
+}
+
+// lookup returns the field selected by key, preferring an exact match of
+// the field's name, and otherwise a case insensitive match, or nil if there
+// is none.
+func (sf *structFields) lookup(key string) *field {
+	if f, ok := sf.byName[key]; ok {
+		return f
+	}
+	for i := range sf.list {
+		if strings.EqualFold(sf.list[i].name, key) {
+			return &sf.list[i]
+		}
+	}
+	return nil
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+// cachedFields returns the decodable fields of the struct type t.
+func cachedFields(t reflect.Type) *structFields {
+	if sf, ok := fieldCache.Load(t); ok {
+		return sf.(*structFields)
+	}
+	sf := &structFields{list: typeFields(t, nil)}
+	sf.byName = make(map[string]*field, len(sf.list))
+	for i := range sf.list {
+		f := &sf.list[i]
+		if _, ok := sf.byName[f.name]; !ok {
+			sf.byName[f.name] = f
+		}
+	}
+	v, _ := fieldCache.LoadOrStore(t, sf)
+	return v.(*structFields)
+}
+
+// typeFields returns the exported fields of the struct type t, named by their
+// json tag if they have one. The fields of embedded structs are promoted, as
+// in encoding/json, and follow those of t itself, so that a field of t hides
+// a field of the same name in an embedded struct.
+func typeFields(t reflect.Type, index []int) []field {
+	var fields, embedded []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		idx := append(index[:len(index):len(index)], i)
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, typeFields(sf.Type, idx)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: idx})
+	}
+	return append(fields, embedded...)
+}
@@ -1,6 +1,9 @@
 package json
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
@@ -87,6 +90,9 @@ func TestDecoderInvalidJSON(t *testing.T) {
 		{json: `--123`},
 		{json: `.1`},
 		{json: `0.1e`},
+		{json: `trueee`},
+		{json: `[nullx]`},
+		{json: `{"a": falsey}`},
 		// fuzz testing
 		// {json: "\"\x00outC: .| >\x185\x014\x80\x00\x01n" +
 		//	"E4255425067\x014\x80\x00\x01.242" +
@@ -219,3 +225,211 @@ func TestDecoderDecode(t *testing.T) {
 		},
 	})
 }
+
+func TestDecoderUnderscores(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"a": 1_000, "b": -2_5.0_5, "c": [1_0]}`))
+	dec.SetAllowUnderscores(true)
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"a": 1000.0,
+		"b": -25.05,
+		"c": []interface{}{10.0},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Fatalf("expected: %v, got: %v", want, v)
+	}
+
+	dec = NewDecoder(strings.NewReader(`{"a": 1_000}`))
+	dec.SetAllowUnderscores(true)
+	m := make(map[string]int)
+	if err := dec.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m["a"] != 1000 {
+		t.Fatalf("expected: %v, got: %v", 1000, m["a"])
+	}
+}
+
+func TestDecoderUseNumber(t *testing.T) {
+	// none of these numbers survive a round trip through float64.
+	const input = `{"big": 12345678901234567891, "precise": 0.10000000000000000001, "list": [9007199254740993, {"n": -1e400}], "int": 1}`
+	dec := NewDecoder(&SmallReader{r: strings.NewReader(input)})
+	dec.SetUseNumber(true)
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"big":     json.Number("12345678901234567891"),
+		"precise": json.Number("0.10000000000000000001"),
+		"list":    []interface{}{json.Number("9007199254740993"), map[string]interface{}{"n": json.Number("-1e400")}},
+		"int":     json.Number("1"),
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Fatalf("expected: %v, got: %v", want, v)
+	}
+
+	dec = NewDecoder(strings.NewReader(`1_000 2`))
+	dec.SetAllowUnderscores(true)
+	dec.SetUseNumber(true)
+	tok, err := dec.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok != json.Number("1000") {
+		t.Fatalf("expected: %v, got: %v", json.Number("1000"), tok)
+	}
+}
+
+func TestDecoderUnexpectedColon(t *testing.T) {
+	tests := []struct {
+		json   string
+		offset int
+	}{
+		{json: `:`, offset: 0},
+		{json: ` :1`, offset: 1},
+		{json: `[1:2]`, offset: 2},
+		{json: `[:]`, offset: 1},
+		{json: `[[1], :]`, offset: 6},
+		{json: `{:}`, offset: 1},
+		{json: `{"a"::1}`, offset: 5},
+		{json: `{"a":1:2}`, offset: 6},
+		{json: `{"a":1,:}`, offset: 7},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			dec := NewDecoder(&SmallReader{r: strings.NewReader(tc.json)})
+			var err error
+			for err == nil {
+				_, err = dec.NextToken()
+			}
+			if !errors.Is(err, ErrUnexpectedColon) {
+				t.Fatalf("expected: %v, got: %v", ErrUnexpectedColon, err)
+			}
+			if want := fmt.Sprintf("at offset %d", tc.offset); !strings.HasSuffix(err.Error(), want) {
+				t.Fatalf("expected: %q, got: %q", want, err)
+			}
+		})
+	}
+
+	// a missing colon is not an unexpected one.
+	for _, in := range []string{`{"a" "b"}`, `{"a" 1}`, `{"a"}`} {
+		dec := NewDecoder(strings.NewReader(in))
+		var err error
+		for err == nil {
+			_, err = dec.NextToken()
+		}
+		if err == io.EOF || errors.Is(err, ErrUnexpectedColon) {
+			t.Fatalf("%s: expected missing colon error, got: %v", in, err)
+		}
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	tests := []struct {
+		json, want string
+	}{
+		{json: ``, want: `stateValue: expecting value: unexpected EOF`},
+		{json: `{`, want: `stateObjectString: expecting string key or object end: unexpected EOF`},
+		{json: `{"key"`, want: `stateObjectColon: expecting colon: unexpected EOF`},
+		{json: `{"key":`, want: `stateObjectValue: expecting value: unexpected EOF`},
+		{json: `{"key":1`, want: `stateObjectComma: expecting comma or object end: unexpected EOF`},
+		{json: `{"key":1,`, want: `stateObjectString: expecting string key or object end: unexpected EOF`},
+		{json: `{"key":[`, want: `stateArrayValue: expecting value or array end: unexpected EOF`},
+		{json: `{"key":[1`, want: `stateArrayComma: expecting comma or array end: unexpected EOF`},
+		{json: `{"ke`, want: `stateObjectString: expecting string key or object end: unexpected EOF`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			dec := NewDecoder(&SmallReader{r: strings.NewReader(tc.json)})
+			var err error
+			for err == nil {
+				_, err = dec.NextToken()
+			}
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("expected: %v, got: %v", io.ErrUnexpectedEOF, err)
+			}
+			if err.Error() != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestDecoderDecodeStruct(t *testing.T) {
+	type Inner struct {
+		N int
+	}
+	type Embedded struct {
+		E string
+		X string
+	}
+	type T struct {
+		Embedded
+		A       string           `json:"a"`
+		B       []int            `json:"b,omitempty"`
+		C       *Inner           `json:"c"`
+		D       [2]bool          `json:"d"`
+		M       map[string]Inner `json:"m"`
+		S       []Inner          `json:"s"`
+		X       string           // hides Embedded.X
+		Skip    string           `json:"-"`
+		private string
+		Any     interface{}       `json:"any"`
+		Nested  map[string][]bool `json:"nested"`
+	}
+	in := `{
+		"a": "café \"quoted\"",
+		"b": [1, 2, 3],
+		"c": {"N": 4},
+		"d": [true, false, true],
+		"m": {"k\n": {"n": 5}},
+		"s": [{"N": 6}, {}],
+		"x": "shallow",
+		"e": "embedded",
+		"Skip": "ignored",
+		"private": "ignored",
+		"unknown": {"deeply": [{"nested": null}]},
+		"any": [1, "two"],
+		"nested": {"t": [true]}
+	}`
+	var got T
+	got.B = []int{9, 9, 9, 9}
+	if err := NewDecoder(&SmallReader{r: strings.NewReader(in)}).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := T{
+		Embedded: Embedded{E: "embedded"},
+		A:        `café "quoted"`,
+		B:        []int{1, 2, 3},
+		C:        &Inner{N: 4},
+		D:        [2]bool{true, false},
+		M:        map[string]Inner{"k\n": {N: 5}},
+		S:        []Inner{{N: 6}, {}},
+		X:        "shallow",
+		Any:      []interface{}{1.0, "two"},
+		Nested:   map[string][]bool{"t": {true}},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("expected: %+v, got: %+v", want, got)
+	}
+
+	var a [3]int
+	a[2] = 7
+	if err := NewDecoder(strings.NewReader(`[1]`)).Decode(&a); err != nil {
+		t.Fatal(err)
+	}
+	if a != [3]int{1, 0, 0} {
+		t.Fatalf("expected: %v, got: %v", [3]int{1, 0, 0}, a)
+	}
+
+	var s struct{ A int }
+	if err := NewDecoder(strings.NewReader(`{"A": "one"}`)).Decode(&s); err == nil {
+		t.Fatalf("expected err, got: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package json
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WriteDelimited reads each remaining top-level value in the stream and
+// writes it to w as a length-delimited record: the length of the value in
+// bytes, encoded as an unsigned varint as in protobuf's writeDelimitedTo,
+// followed by the value's bytes exactly as they appear in the stream.
+// Whitespace between top-level values is not written.
+//
+// As with NextValue, brackets are checked for nesting but the structure of
+// each value is not otherwise validated.
+// WriteDelimited returns nil once the stream is exhausted, or the first error
+// encountered reading the stream or writing to w.
+func (s *Scanner) WriteDelimited(w io.Writer) error {
+	var buf []byte
+	var hdr [binary.MaxVarintLen64]byte
+	for {
+		var err error
+		buf, err = s.appendValue(buf[:0])
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return fmt.Errorf("WriteDelimited: %w", err)
+		}
+		n := binary.PutUvarint(hdr[:], uint64(len(buf)))
+		if _, err := w.Write(hdr[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+}
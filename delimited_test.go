@@ -0,0 +1,79 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScannerWriteDelimited(t *testing.T) {
+	tests := []struct {
+		json string
+		want []string
+	}{
+		{json: ``, want: nil},
+		{json: " \n", want: nil},
+		{json: `1`, want: []string{`1`}},
+		{json: "{\"a\": [1, 2]}\n{\"b\":\tnull}\n", want: []string{`{"a": [1, 2]}`, "{\"b\":\tnull}"}},
+		{json: `"x" true [ ] {"s": "` + strings.Repeat("y", 300) + `"}`, want: []string{`"x"`, `true`, `[ ]`, `{"s": "` + strings.Repeat("y", 300) + `"}`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			var buf bytes.Buffer
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			if err := sc.WriteDelimited(&buf); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			r := bufio.NewReader(&buf)
+			for {
+				n, err := binary.ReadUvarint(r)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				value := make([]byte, n)
+				if _, err := io.ReadFull(r, value); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, string(value))
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected: %q, got: %q", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("expected: %q, got: %q", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestScannerWriteDelimitedInvalid(t *testing.T) {
+	tests := []struct {
+		json string
+		err  error
+	}{
+		{json: `[1, 2`, err: io.ErrUnexpectedEOF},
+		{json: `{"a": 1]`},
+		{json: `1, 2`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(strings.NewReader(tc.json))
+			err := sc.WriteDelimited(io.Discard)
+			if err == nil || tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
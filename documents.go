@@ -0,0 +1,43 @@
+package json
+
+import "fmt"
+
+// RawMessage is a raw encoded JSON value.
+type RawMessage []byte
+
+// ScanDocuments splits b, a sequence of whitespace separated JSON values,
+// into its top-level values. Each RawMessage is a slice of b holding one
+// complete value, excluding the surrounding whitespace; no bytes are copied.
+// Every value is validated, and if b holds anything other than complete
+// values, ScanDocuments returns the values before it along with the error.
+// If b is empty, or only whitespace, ScanDocuments returns no values and a nil
+// error.
+func ScanDocuments(b []byte) ([]RawMessage, error) {
+	docs, err := NewScannerBytes(b).documents(b, nil)
+	if err != nil {
+		return docs, fmt.Errorf("ScanDocuments: %w", err)
+	}
+	return docs, nil
+}
+
+// documents appends the top-level values in b to docs, as slices of b. The
+// scanner must be positioned at the start of b.
+func (s *Scanner) documents(b []byte, docs []RawMessage) ([]RawMessage, error) {
+	var start int64
+	for {
+		tok := s.Next()
+		if len(tok) < 1 {
+			return docs, s.validEnd()
+		}
+		if s.valid.complete() {
+			start = s.br.pos()
+		}
+		if err := s.valid.step(tok); err != nil {
+			return docs, fmt.Errorf("%w at offset %d", err, s.br.pos())
+		}
+		if s.valid.complete() {
+			end := s.br.pos() + int64(len(tok))
+			docs = append(docs, b[start:end:end])
+		}
+	}
+}
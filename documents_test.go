@@ -0,0 +1,80 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestScanDocuments(t *testing.T) {
+	tests := []struct {
+		json string
+		want []string
+	}{
+		{json: ``},
+		{json: " \n\t"},
+		{json: `1`, want: []string{`1`}},
+		{json: `true false null`, want: []string{`true`, `false`, `null`}},
+		{json: "{\"a\": [1, 2]}\n{\"b\":\tnull}\n", want: []string{`{"a": [1, 2]}`, "{\"b\":\tnull}"}},
+		{json: ` "x"[ ]{}-1.5e3 `, want: []string{`"x"`, `[ ]`, `{}`, `-1.5e3`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			docs, err := ScanDocuments([]byte(tc.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(docs) != len(tc.want) {
+				t.Fatalf("expected: %q, got: %q", tc.want, docs)
+			}
+			for i := range docs {
+				if string(docs[i]) != tc.want[i] {
+					t.Fatalf("expected: %q, got: %q", tc.want, docs)
+				}
+			}
+		})
+	}
+}
+
+func TestScanDocumentsSlicesInput(t *testing.T) {
+	b := []byte(`{"a": 1} [2]`)
+	docs, err := ScanDocuments(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &docs[1][0] != &b[9] {
+		t.Fatalf("expected a slice of the input")
+	}
+	// appending to a document must not overwrite the next.
+	_ = append(docs[0], 'x')
+	if b[8] != ' ' {
+		t.Fatalf("expected: %q, got: %q", ' ', b[8])
+	}
+}
+
+func TestScanDocumentsInvalid(t *testing.T) {
+	tests := []struct {
+		json string
+		docs int
+		err  error
+	}{
+		{json: `{"a": 1} {"b"`, docs: 1, err: io.ErrUnexpectedEOF},
+		{json: `1 2 [3,`, docs: 2, err: io.ErrUnexpectedEOF},
+		{json: `[1] ]`, docs: 1},
+		{json: `{"a": 1} garbage`, docs: 1},
+		{json: `1, 2`, docs: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			docs, err := ScanDocuments([]byte(tc.json))
+			if err == nil || tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+			if len(docs) != tc.docs {
+				t.Fatalf("expected: %d documents, got: %q", tc.docs, docs)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// An Encoder writes a stream of JSON tokens to an output stream, inserting the
+// colons and commas between them. Successive top-level values are separated
+// by newlines. Output is buffered; call Flush once the last token has been
+// written.
+//
+// The Encoder checks that tokens are written in an order which forms well
+// formed JSON: keys only within objects, where each key must be followed by
+// a value, and closing brackets which match the innermost open bracket. Once
+// a method returns an error, all further calls return the same error.
+type Encoder struct {
+	w     io.Writer
+	buf   []byte
+	stack       // open containers, true for objects
+	state uint8 // what may be written next
+	err   error
+}
+
+// Encoder states.
+const (
+	encFirst = iota // following an opening bracket, or at the start of the stream
+	encValue        // following a key
+	encNext         // following a complete value
+)
+
+// encoderBufferSize is the number of bytes an Encoder buffers before writing
+// them to the underlying io.Writer.
+const encoderBufferSize = 4096
+
+// NewEncoder returns a new Encoder which writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// WriteObjectStart writes the start of an object, {.
+func (e *Encoder) WriteObjectStart() error {
+	if err := e.value("object start"); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, ObjectStart)
+	e.push(true)
+	e.state = encFirst
+	return nil
+}
+
+// WriteObjectEnd writes the end of the innermost open object, }.
+func (e *Encoder) WriteObjectEnd() error {
+	return e.end(true)
+}
+
+// WriteArrayStart writes the start of an array, [.
+func (e *Encoder) WriteArrayStart() error {
+	if err := e.value("array start"); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, ArrayStart)
+	e.push(false)
+	e.state = encFirst
+	return nil
+}
+
+// WriteArrayEnd writes the end of the innermost open array, ].
+func (e *Encoder) WriteArrayEnd() error {
+	return e.end(false)
+}
+
+// WriteKey writes key, escaped as by AppendEscaped, as the key of the next
+// member of the innermost open object, followed by a colon.
+func (e *Encoder) WriteKey(key string) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.len() == 0 || !e.stack[e.len()-1] || e.state == encValue {
+		return e.fail("key")
+	}
+	if e.state != encFirst {
+		e.buf = append(e.buf, Comma)
+	}
+	e.buf = AppendEscaped(e.buf, key)
+	e.buf = append(e.buf, Colon)
+	e.state = encValue
+	return nil
+}
+
+// WriteString writes s as a string, escaped as by AppendEscaped.
+func (e *Encoder) WriteString(s string) error {
+	if err := e.value("string"); err != nil {
+		return err
+	}
+	e.buf = AppendEscaped(e.buf, s)
+	return e.scalar()
+}
+
+// WriteNumber writes the number n, which must be a valid JSON number such as
+// -1.5e3, as is.
+func (e *Encoder) WriteNumber(n string) error {
+	if e.err == nil {
+		if _, ok := numberKind([]byte(n)); !ok {
+			e.err = fmt.Errorf("Encoder: invalid number %q", n)
+		}
+	}
+	if err := e.value("number"); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, n...)
+	return e.scalar()
+}
+
+// WriteInt writes the integer i as a number.
+func (e *Encoder) WriteInt(i int64) error {
+	if err := e.value("number"); err != nil {
+		return err
+	}
+	e.buf = strconv.AppendInt(e.buf, i, 10)
+	return e.scalar()
+}
+
+// WriteFloat writes f as a number, in the shortest form which represents it
+// exactly. NaN and ±Inf, which JSON cannot represent, are an error.
+func (e *Encoder) WriteFloat(f float64) error {
+	if e.err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		e.err = fmt.Errorf("Encoder: unsupported value %v", f)
+	}
+	if err := e.value("number"); err != nil {
+		return err
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, 'g', -1, 64)
+	return e.scalar()
+}
+
+// WriteBool writes true or false.
+func (e *Encoder) WriteBool(v bool) error {
+	if err := e.value("bool"); err != nil {
+		return err
+	}
+	e.buf = strconv.AppendBool(e.buf, v)
+	return e.scalar()
+}
+
+// WriteNull writes null.
+func (e *Encoder) WriteNull() error {
+	if err := e.value("null"); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, "null"...)
+	return e.scalar()
+}
+
+// Flush writes any buffered output to the underlying io.Writer.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	if _, err := e.w.Write(e.buf); err != nil {
+		e.err = err
+		return err
+	}
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// value checks that a value, described by what, may be written next, and
+// writes the separator which precedes it.
+func (e *Encoder) value(what string) error {
+	if e.err != nil {
+		return e.err
+	}
+	inObj := e.len() > 0 && e.stack[e.len()-1]
+	switch {
+	case inObj && e.state != encValue:
+		return e.fail(what)
+	case e.state == encNext && e.len() == 0:
+		e.buf = append(e.buf, '\n')
+	case e.state == encNext:
+		e.buf = append(e.buf, Comma)
+	}
+	return nil
+}
+
+// scalar completes a value, writing the buffered output once enough of it
+// has accumulated.
+func (e *Encoder) scalar() error {
+	e.state = encNext
+	if len(e.buf) >= encoderBufferSize {
+		return e.Flush()
+	}
+	return nil
+}
+
+// end closes the innermost open container, an object if obj is true or an
+// array.
+func (e *Encoder) end(obj bool) error {
+	what := "array end"
+	if obj {
+		what = "object end"
+	}
+	if e.err != nil {
+		return e.err
+	}
+	if e.len() == 0 || e.stack[e.len()-1] != obj || e.state == encValue {
+		return e.fail(what)
+	}
+	e.pop()
+	if obj {
+		e.buf = append(e.buf, ObjectEnd)
+	} else {
+		e.buf = append(e.buf, ArrayEnd)
+	}
+	return e.scalar()
+}
+
+// fail records and returns the error for writing what at this point.
+func (e *Encoder) fail(what string) error {
+	expected := "value"
+	switch {
+	case e.state == encValue:
+		expected = "value following key"
+	case e.len() > 0 && e.stack[e.len()-1]:
+		expected = "key or object end"
+	case e.len() > 0:
+		expected = "value or array end"
+	}
+	e.err = fmt.Errorf("Encoder: unexpected %s, expected %s", what, expected)
+	return e.err
+}
@@ -0,0 +1,120 @@
+package json
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	check(t, e.WriteObjectStart())
+	check(t, e.WriteKey("a"))
+	check(t, e.WriteArrayStart())
+	check(t, e.WriteInt(1))
+	check(t, e.WriteFloat(-2.5))
+	check(t, e.WriteNumber("3e10"))
+	check(t, e.WriteString("four\n\"4\""))
+	check(t, e.WriteBool(true))
+	check(t, e.WriteNull())
+	check(t, e.WriteObjectStart())
+	check(t, e.WriteObjectEnd())
+	check(t, e.WriteArrayStart())
+	check(t, e.WriteArrayEnd())
+	check(t, e.WriteArrayEnd())
+	check(t, e.WriteKey("b\x01"))
+	check(t, e.WriteBool(false))
+	check(t, e.WriteObjectEnd())
+	check(t, e.WriteInt(2))
+	if buf.Len() > 0 {
+		t.Fatalf("expected output to be buffered, got: %q", buf.String())
+	}
+	check(t, e.Flush())
+	want := `{"a":[1,-2.5,3e10,"four\n\"4\"",true,null,{},[]],"b\u0001":false}` + "\n2"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected: %s, got: %s", want, got)
+	}
+	if !Valid(buf.Bytes()[:strings.IndexByte(buf.String(), '\n')]) {
+		t.Fatalf("expected valid JSON, got: %s", buf.String())
+	}
+}
+
+func TestEncoderWriteString(t *testing.T) {
+	for _, s := range []string{``, `plain`, "quote \" backslash \\ slash /", "\x00\x1f\t\r\n\b\f", "café 😀", "\u2028\u2029"} {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		check(t, e.WriteString(s))
+		check(t, e.Flush())
+		got, err := Unescape(buf.Bytes())
+		check(t, err)
+		if got != s {
+			t.Fatalf("expected: %q, got: %q from %s", s, got, buf.String())
+		}
+	}
+}
+
+func TestEncoderFlush(t *testing.T) {
+	// output is written once the buffer fills.
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	check(t, e.WriteArrayStart())
+	for buf.Len() == 0 {
+		check(t, e.WriteString(strings.Repeat("x", 100)))
+	}
+	check(t, e.WriteArrayEnd())
+	check(t, e.Flush())
+	sc := NewScannerBytes(buf.Bytes())
+	if err := sc.Discard(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncoderInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(e *Encoder) error
+		want  string
+	}{
+		{"key at top level", func(e *Encoder) error { return e.WriteKey("a") }, `Encoder: unexpected key, expected value`},
+		{"key in array", func(e *Encoder) error {
+			e.WriteArrayStart()
+			return e.WriteKey("a")
+		}, `Encoder: unexpected key, expected value or array end`},
+		{"value without key", func(e *Encoder) error {
+			e.WriteObjectStart()
+			return e.WriteInt(1)
+		}, `Encoder: unexpected number, expected key or object end`},
+		{"key after key", func(e *Encoder) error {
+			e.WriteObjectStart()
+			e.WriteKey("a")
+			return e.WriteKey("b")
+		}, `Encoder: unexpected key, expected value following key`},
+		{"end after key", func(e *Encoder) error {
+			e.WriteObjectStart()
+			e.WriteKey("a")
+			return e.WriteObjectEnd()
+		}, `Encoder: unexpected object end, expected value following key`},
+		{"mismatched end", func(e *Encoder) error {
+			e.WriteArrayStart()
+			return e.WriteObjectEnd()
+		}, `Encoder: unexpected object end, expected value or array end`},
+		{"end at top level", func(e *Encoder) error { return e.WriteArrayEnd() }, `Encoder: unexpected array end, expected value`},
+		{"invalid number", func(e *Encoder) error { return e.WriteNumber("01") }, `Encoder: invalid number "01"`},
+		{"NaN", func(e *Encoder) error { return e.WriteFloat(math.NaN()) }, `Encoder: unsupported value NaN`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEncoder(new(bytes.Buffer))
+			if err := tc.write(e); err == nil || err.Error() != tc.want {
+				t.Fatalf("expected: %q, got: %v", tc.want, err)
+			}
+			// the error is sticky.
+			if err := e.WriteNull(); err == nil || err.Error() != tc.want {
+				t.Fatalf("expected: %q, got: %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// EqualValue reads a single JSON value from r and reports whether it is
+// structurally equal to expected, a Go value built from maps with string keys,
+// slices and arrays, strings, booleans, numbers of any width, and nil, as
+// produced for example by decoding into an interface{}.
+// The streamed value is compared as it is read, without being held in memory,
+// and EqualValue stops reading at the first difference.
+//
+// Numbers are compared according to the type of the expected value: exactly
+// for integers, and after conversion to the expected width for floats. A nil
+// map, slice, pointer or interface is equal to null. Object members may appear
+// in any order.
+//
+// If the values differ, EqualValue returns false and an error wrapping
+// ErrMismatch which describes the path of the first difference, e.g.
+// $.items[3].name. Any other error is a failure to read or parse r.
+func EqualValue(r io.Reader, expected any) (bool, error) {
+	d := NewDecoder(r)
+	var (
+		frames []equalFrame
+		ev     = reflect.ValueOf(expected)
+		path   = "$"
+	)
+	for {
+		tok, err := d.NextToken()
+		if err != nil {
+			return false, err
+		}
+		if n := len(frames); n > 0 {
+			top := &frames[n-1]
+			switch tok[0] {
+			case ObjectEnd:
+				if missing, ok := top.missing(); ok {
+					return false, mismatch(appendKey(top.path, missing), "member", "object end")
+				}
+				frames = frames[:n-1]
+			case ArrayEnd:
+				if top.n != top.v.Len() {
+					return false, mismatch(top.path, fmt.Sprintf("%d elements", top.v.Len()), fmt.Sprintf("%d elements", top.n))
+				}
+				frames = frames[:n-1]
+			default:
+				if top.v.Kind() == reflect.Map {
+					key, err := Unescape(tok)
+					if err != nil {
+						return false, err
+					}
+					path = appendKey(top.path, key)
+					kv := reflect.ValueOf(key).Convert(top.v.Type().Key())
+					if ev = top.v.MapIndex(kv); !ev.IsValid() {
+						return false, mismatch(path, "no member", "member")
+					}
+					top.seen[key] = true
+					if tok, err = d.NextToken(); err != nil {
+						return false, err
+					}
+				} else {
+					path = fmt.Sprintf("%s[%d]", top.path, top.n)
+					if top.n >= top.v.Len() {
+						return false, mismatch(path, "array end", describe(tok))
+					}
+					ev = top.v.Index(top.n)
+					top.n++
+				}
+				frame, err := equalToken(tok, ev, path)
+				if err != nil {
+					return false, err
+				}
+				if frame.v.IsValid() {
+					frames = append(frames, frame)
+				}
+			}
+			if len(frames) == 0 {
+				return true, nil
+			}
+			continue
+		}
+
+		// the top-level value.
+		frame, err := equalToken(tok, ev, path)
+		if err != nil {
+			return false, err
+		}
+		if !frame.v.IsValid() {
+			return true, nil
+		}
+		frames = append(frames, frame)
+	}
+}
+
+// An equalFrame records the progress of EqualValue through an open object or
+// array of the expected value.
+type equalFrame struct {
+	v    reflect.Value   // the expected map, slice or array
+	path string          // the path of the container
+	n    int             // array elements seen so far
+	seen map[string]bool // object members seen so far
+}
+
+// missing returns the first, in sorted order, of the expected keys which
+// have not been seen, if any.
+func (f *equalFrame) missing() (string, bool) {
+	if len(f.seen) == f.v.Len() {
+		return "", false
+	}
+	var keys []string
+	for _, k := range f.v.MapKeys() {
+		if !f.seen[k.String()] {
+			keys = append(keys, k.String())
+		}
+	}
+	sort.Strings(keys)
+	return keys[0], true
+}
+
+// equalToken compares the token tok, which starts a value, with the expected
+// value ev at path. If tok opens an object or array, the frame for its
+// content is returned; otherwise the returned frame is the zero value.
+func equalToken(tok []byte, ev reflect.Value, path string) (equalFrame, error) {
+	for ev.Kind() == reflect.Interface || ev.Kind() == reflect.Ptr {
+		if ev.IsNil() {
+			break
+		}
+		ev = ev.Elem()
+	}
+	switch kind := ev.Kind(); {
+	case kind == reflect.Map && ev.Type().Key().Kind() != reflect.String,
+		kind == reflect.Struct, kind == reflect.Chan, kind == reflect.Func, kind == reflect.Complex64, kind == reflect.Complex128:
+		return equalFrame{}, fmt.Errorf("EqualValue: unsupported expected type %v at %s", ev.Type(), path)
+	}
+
+	switch tok[0] {
+	case ObjectStart:
+		if ev.Kind() == reflect.Map && !ev.IsNil() {
+			return equalFrame{v: ev, path: path, seen: make(map[string]bool, ev.Len())}, nil
+		}
+	case ArrayStart:
+		if ev.Kind() == reflect.Array || ev.Kind() == reflect.Slice && !ev.IsNil() {
+			return equalFrame{v: ev, path: path}, nil
+		}
+	case True, False:
+		if ev.Kind() == reflect.Bool && ev.Bool() == (tok[0] == True) {
+			return equalFrame{}, nil
+		}
+	case Null:
+		switch ev.Kind() {
+		case reflect.Invalid:
+			return equalFrame{}, nil
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			if ev.IsNil() {
+				return equalFrame{}, nil
+			}
+		}
+	case String:
+		if ev.Kind() == reflect.String {
+			s, err := Unescape(tok)
+			if err != nil {
+				return equalFrame{}, err
+			}
+			if s == ev.String() {
+				return equalFrame{}, nil
+			}
+		}
+	default:
+		n := bytesToString(tok)
+		switch ev.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if i, err := strconv.ParseInt(n, 10, 64); err == nil && i == ev.Int() {
+				return equalFrame{}, nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if u, err := strconv.ParseUint(n, 10, 64); err == nil && u == ev.Uint() {
+				return equalFrame{}, nil
+			}
+		case reflect.Float32, reflect.Float64:
+			if f, err := strconv.ParseFloat(n, ev.Type().Bits()); err == nil && f == ev.Float() {
+				return equalFrame{}, nil
+			}
+		}
+	}
+	return equalFrame{}, mismatch(path, describeExpected(ev), describe(tok))
+}
+
+// describeExpected returns a short description of the expected value ev, for
+// use in mismatch errors.
+func describeExpected(ev reflect.Value) string {
+	switch ev.Kind() {
+	case reflect.Invalid:
+		return "null"
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		if ev.IsNil() {
+			return "null"
+		}
+	}
+	switch ev.Kind() {
+	case reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Bool:
+		return strconv.FormatBool(ev.Bool())
+	case reflect.String:
+		return fmt.Sprintf("string %q", ev.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("number %d", ev.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprintf("number %d", ev.Uint())
+	default:
+		return "number " + strconv.FormatFloat(ev.Float(), 'g', -1, ev.Type().Bits())
+	}
+}
+
+func mismatch(path, want, got string) error {
+	return fmt.Errorf("EqualValue: %w at %s: expected %s, got %s", ErrMismatch, path, want, got)
+}
+
+// appendKey returns the path of the member key of the object at path.
+func appendKey(path, key string) string {
+	for i := 0; i < len(key); i++ {
+		if !identifier[key[i]] {
+			return path + "[" + strconv.Quote(key) + "]"
+		}
+	}
+	if key == "" {
+		return path + `[""]`
+	}
+	return path + "." + key
+}
@@ -0,0 +1,90 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEqualValue(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected any
+	}{
+		{json: `null`, expected: nil},
+		{json: `true`, expected: true},
+		{json: `"hello"`, expected: "hello"},
+		{json: `"café \"x\""`, expected: "café \"x\""},
+		{json: `12`, expected: 12},
+		{json: `12`, expected: uint8(12)},
+		{json: `1.5e2`, expected: 150.0},
+		{json: `0.1`, expected: float32(0.1)},
+		{json: `[]`, expected: []any{}},
+		{json: `[1, "a", null]`, expected: []any{1, "a", nil}},
+		{json: `[1, 2]`, expected: [2]int{1, 2}},
+		{json: `{}`, expected: map[string]any{}},
+		{json: `{"b": 2, "a": [true, {"c": null}]}`, expected: map[string]any{"a": []any{true, map[string]any{"c": nil}}, "b": 2}},
+		{json: `{"a": null, "b": null}`, expected: map[string]any{"a": []int(nil), "b": (*int)(nil)}},
+		{json: `{"n": 1}`, expected: map[string]float64{"n": 1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			ok, err := EqualValue(&SmallReader{r: strings.NewReader(tc.json)}, tc.expected)
+			if !ok || err != nil {
+				t.Fatalf("expected: true, <nil>, got: %v, %v", ok, err)
+			}
+		})
+	}
+}
+
+func TestEqualValueMismatch(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected any
+		want     string
+	}{
+		{json: `null`, expected: 0, want: `at $: expected number 0, got null`},
+		{json: `12`, expected: 13, want: `at $: expected number 13, got number 12`},
+		{json: `1.5`, expected: 1, want: `at $: expected number 1, got number 1.5`},
+		{json: `"a"`, expected: "b", want: `at $: expected string "b", got string "a"`},
+		{json: `[1, 2]`, expected: []any{1, 3}, want: `at $[1]: expected number 3, got number 2`},
+		{json: `[1, 2]`, expected: []any{1}, want: `at $[1]: expected array end, got number 2`},
+		{json: `[1]`, expected: []any{1, 2}, want: `at $: expected 2 elements, got 1 elements`},
+		{json: `{"a": {"b": true}}`, expected: map[string]any{"a": map[string]any{"b": false}}, want: `at $.a.b: expected false, got true`},
+		{json: `{"a": 1, "x y": 2}`, expected: map[string]any{"a": 1}, want: `at $["x y"]: expected no member, got member`},
+		{json: `{"a": 1}`, expected: map[string]any{"a": 1, "c": 2, "b": 3}, want: `at $.b: expected member, got object end`},
+		{json: `{}`, expected: []any{}, want: `at $: expected array, got object start '{'`},
+		{json: `[]`, expected: map[string]any(nil), want: `at $: expected null, got array start '['`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			ok, err := EqualValue(strings.NewReader(tc.json), tc.expected)
+			if ok || !errors.Is(err, ErrMismatch) {
+				t.Fatalf("expected: false, %v, got: %v, %v", ErrMismatch, ok, err)
+			}
+			if want := "EqualValue: values differ " + tc.want; err.Error() != want {
+				t.Fatalf("expected: %q, got: %q", want, err)
+			}
+		})
+	}
+}
+
+func TestEqualValueShortCircuit(t *testing.T) {
+	// the stream is invalid after the first difference, which must not be read.
+	ok, err := EqualValue(strings.NewReader(`[1, 2, }`), []any{1, 3})
+	if ok || !errors.Is(err, ErrMismatch) {
+		t.Fatalf("expected: false, %v, got: %v, %v", ErrMismatch, ok, err)
+	}
+}
+
+func TestEqualValueInvalid(t *testing.T) {
+	ok, err := EqualValue(strings.NewReader(`[1, 2`), []any{1, 2})
+	if ok || err == nil || errors.Is(err, ErrMismatch) {
+		t.Fatalf("expected: false and a syntax error, got: %v, %v", ok, err)
+	}
+	if _, err := EqualValue(strings.NewReader(`{}`), struct{}{}); err == nil || errors.Is(err, ErrMismatch) {
+		t.Fatalf("expected: unsupported type error, got: %v", err)
+	}
+}
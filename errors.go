@@ -0,0 +1,61 @@
+package json
+
+import "errors"
+
+// A SyntaxError is reported by Scanner.Error when the input holds a malformed
+// token, one which is not merely a valid token truncated by the end of the
+// stream.
+type SyntaxError struct {
+	Offset int64 // stream offset of the offending byte
+	Byte   byte  // the offending byte
+	err    error // the description, which may wrap a sentinel error
+}
+
+func (e *SyntaxError) Error() string { return e.err.Error() }
+
+// Unwrap returns the underlying error, so that errors.Is reports whether the
+// syntax error is, for example, ErrInvalidEscape.
+func (e *SyntaxError) Unwrap() error { return e.err }
+
+// ErrUnexpectedColon is returned when a colon appears anywhere other than
+// between an object key and its value.
+var ErrUnexpectedColon = errors.New("unexpected colon")
+
+// ErrDisallowedTopLevel is returned when a top-level value is not of one of
+// the types permitted by Scanner.SetAllowedTopLevel.
+var ErrDisallowedTopLevel = errors.New("disallowed top-level value")
+
+// ErrMismatch is returned by EqualValue when the streamed value differs from
+// the expected value.
+var ErrMismatch = errors.New("values differ")
+
+// ErrInvalidUTF8 is returned when the input is not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+// ErrControlCharacter is returned when a string contains an unescaped control
+// character, U+0000 to U+001F.
+var ErrControlCharacter = errors.New("control character")
+
+// ErrInvalidEscape is returned when a string contains a backslash which does
+// not start a valid escape sequence.
+var ErrInvalidEscape = errors.New("invalid escape sequence")
+
+// ErrMaxDepthExceeded is returned when objects and arrays are nested more
+// deeply than permitted by Scanner.SetMaxDepth.
+var ErrMaxDepthExceeded = errors.New("maximum nesting depth exceeded")
+
+// ErrDuplicateKey is returned when a key appears more than once in the same
+// object, with Scanner.SetDisallowDuplicateKeys.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// ErrInputTooLarge is returned when the input is longer than permitted by
+// Scanner.SetMaxTotalBytes.
+var ErrInputTooLarge = errors.New("input too large")
+
+// ErrTokenTooLarge is returned when a token is longer than permitted by
+// Scanner.SetMaxTokenSize.
+var ErrTokenTooLarge = errors.New("token too large")
+
+// ErrTrailingData is returned by Scanner.Done when anything but whitespace
+// follows a top-level value.
+var ErrTrailingData = errors.New("trailing data after top-level value")
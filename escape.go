@@ -0,0 +1,176 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// AppendEscaped appends s to dst as a JSON string, surrounded by quotes, and
+// returns the extended buffer. Quotes, backslashes and control characters are
+// escaped, as are U+2028 and U+2029 which some JavaScript parsers reject.
+// Invalid UTF-8 is replaced by U+FFFD.
+func AppendEscaped(dst []byte, s string) []byte {
+	return appendEscaped(dst, s, false)
+}
+
+// AppendHTMLEscaped is like AppendEscaped but additionally escapes <, >, and
+// & so the result may be safely embedded in HTML, as encoding/json does by
+// default.
+func AppendHTMLEscaped(dst []byte, s string) []byte {
+	return appendEscaped(dst, s, true)
+}
+
+func appendEscaped(dst []byte, s string, html bool) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!html || c != '<' && c != '>' && c != '&') {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// Unescape returns the value of the string token, as returned by Next: the
+// content between the quotes with all escape sequences resolved, including
+// UTF-16 surrogate pairs such as \uD83D\uDE00.
+// Unescape returns an error wrapping ErrInvalidEscape if token contains an
+// invalid escape sequence, or a surrogate which is not part of a pair.
+func Unescape(token []byte) (string, error) {
+	if len(token) >= 2 && bytes.IndexByte(token, '\\') < 0 {
+		// fast path, nothing to unescape.
+		if token[0] != '"' || token[len(token)-1] != '"' {
+			return "", fmt.Errorf("Unescape: expected string, got %s", describe(token))
+		}
+		return string(token[1 : len(token)-1]), nil
+	}
+	buf, err := AppendUnescaped(nil, token)
+	return string(buf), err
+}
+
+// AppendUnescaped appends the value of the string token to dst, as Unescape
+// does, and returns the extended buffer.
+func AppendUnescaped(dst, token []byte) ([]byte, error) {
+	if len(token) < 2 || token[0] != '"' || token[len(token)-1] != '"' {
+		return dst, fmt.Errorf("Unescape: expected string, got %s", describe(token))
+	}
+	s := token[1 : len(token)-1]
+	for {
+		i := bytes.IndexByte(s, '\\')
+		if i < 0 {
+			return append(dst, s...), nil
+		}
+		dst = append(dst, s[:i]...)
+		s = s[i:]
+		if len(s) < 2 {
+			return dst, unescapeErr("truncated escape", s)
+		}
+		n := 2
+		switch c := s[1]; c {
+		case '"', '\\', '/':
+			dst = append(dst, c)
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r, ok := hexRune(s)
+			if !ok {
+				return dst, unescapeErr("invalid", s)
+			}
+			n = 6
+			switch {
+			case utf16.IsSurrogate(r) && r < 0xdc00:
+				// a high surrogate must be followed by a low one.
+				r2, ok := hexRune(s[6:])
+				if !ok || r2 < 0xdc00 || r2 > 0xdfff {
+					return dst, unescapeErr("unpaired surrogate", s)
+				}
+				r = utf16.DecodeRune(r, r2)
+				n = 12
+			case utf16.IsSurrogate(r):
+				return dst, unescapeErr("unpaired surrogate", s)
+			}
+			dst = utf8.AppendRune(dst, r)
+		default:
+			return dst, unescapeErr("invalid", s)
+		}
+		s = s[n:]
+	}
+}
+
+// hexRune decodes the \uXXXX escape sequence at the start of s.
+func hexRune(s []byte) (rune, bool) {
+	if len(s) < 6 || s[0] != '\\' || s[1] != 'u' {
+		return 0, false
+	}
+	var r rune
+	for _, c := range s[2:6] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c|0x20 >= 'a' && c|0x20 <= 'f':
+			c = c | 0x20 - 'a' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// unescapeErr returns the error for the escape sequence at the start of s.
+func unescapeErr(reason string, s []byte) error {
+	if len(s) > 6 {
+		s = s[:6]
+	}
+	return fmt.Errorf("Unescape: %w: %s %q", ErrInvalidEscape, reason, s)
+}
@@ -0,0 +1,137 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAppendEscaped(t *testing.T) {
+	tests := []struct {
+		in, want, html string
+	}{
+		{in: ``, want: `""`},
+		{in: `abc`, want: `"abc"`},
+		{in: `a"b\c`, want: `"a\"b\\c"`},
+		{in: "\b\f\n\r\t", want: `"\b\f\n\r\t"`},
+		{in: "\x00\x01\x1f", want: `"\u0000\u0001\u001f"`},
+		{in: `<a href="x">&</a>`, want: `"<a href=\"x\">&</a>"`, html: `"\u003ca href=\"x\"\u003e\u0026\u003c/a\u003e"`},
+		{in: "é😀\u2028\u2029", want: `"é😀\u2028\u2029"`},
+		{in: "a\xffb", want: `"a\ufffdb"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			if got := AppendEscaped(nil, tc.in); string(got) != tc.want {
+				t.Fatalf("expected: %s, got: %s", tc.want, got)
+			}
+			html := tc.html
+			if html == "" {
+				html = tc.want
+			}
+			if got := AppendHTMLEscaped([]byte("x"), tc.in); string(got) != "x"+html {
+				t.Fatalf("expected: %s, got: %s", "x"+html, got)
+			}
+		})
+	}
+}
+
+func TestAppendEscapedRoundTrip(t *testing.T) {
+	var all strings.Builder
+	for r := rune(0); r < 0x800; r++ {
+		all.WriteRune(r)
+	}
+	tests := []string{
+		all.String(),
+		"\U0001F600 \ufffd \u2028\u2029",
+		strings.Repeat(`"\`, 100),
+	}
+
+	for _, in := range tests {
+		for _, escaped := range [][]byte{AppendEscaped(nil, in), AppendHTMLEscaped(nil, in)} {
+			sc := NewScanner(bytes.NewReader(escaped))
+			tok := sc.Next()
+			if !bytes.Equal(tok, escaped) {
+				t.Fatalf("expected: %q, got: %q", escaped, tok)
+			}
+			var got string
+			check(t, json.Unmarshal(tok, &got))
+			if got != in {
+				t.Fatalf("expected: %q, got: %q", in, got)
+			}
+			got, err := Unescape(tok)
+			check(t, err)
+			if got != in {
+				t.Fatalf("Unescape: expected: %q, got: %q", in, got)
+			}
+		}
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: `""`, want: ``},
+		{in: `"abc"`, want: `abc`},
+		{in: `"a\"b\\c\/d"`, want: `a"b\c/d`},
+		{in: `"\b\f\n\r\t"`, want: "\b\f\n\r\t"},
+		{in: `"\u0041\u00e9\u00E9\u20ac"`, want: "Aéé€"},
+		{in: `"\u0000"`, want: "\x00"},
+		{in: `"\uD83D\uDE00"`, want: "\U0001F600"},
+		{in: `"x\ud83d\ude00y"`, want: "x\U0001F600y"},
+		{in: `"é😀"`, want: "é😀"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := Unescape([]byte(tc.in))
+			check(t, err)
+			if got != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, got)
+			}
+			buf, err := AppendUnescaped([]byte("prefix"), []byte(tc.in))
+			check(t, err)
+			if string(buf) != "prefix"+tc.want {
+				t.Fatalf("expected: %q, got: %q", "prefix"+tc.want, buf)
+			}
+		})
+	}
+}
+
+func TestUnescapeInvalid(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: `"\x"`, want: `Unescape: invalid escape sequence: invalid "\\x"`},
+		{in: `"\u12"`, want: `Unescape: invalid escape sequence: invalid "\\u12"`},
+		{in: `"\u12G4"`, want: `Unescape: invalid escape sequence: invalid "\\u12G4"`},
+		{in: `"\uD83D"`, want: `Unescape: invalid escape sequence: unpaired surrogate "\\uD83D"`},
+		{in: `"\uD83Dx"`, want: `Unescape: invalid escape sequence: unpaired surrogate "\\uD83D"`},
+		{in: `"\uD83D\u0041"`, want: `Unescape: invalid escape sequence: unpaired surrogate "\\uD83D"`},
+		{in: `"\uD83D\uD83D"`, want: `Unescape: invalid escape sequence: unpaired surrogate "\\uD83D"`},
+		{in: `"\uD83D\uDE"`, want: `Unescape: invalid escape sequence: unpaired surrogate "\\uD83D"`},
+		{in: `"\uDE00"`, want: `Unescape: invalid escape sequence: unpaired surrogate "\\uDE00"`},
+		{in: `"\"`, want: `Unescape: invalid escape sequence: truncated escape "\\"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			_, err := Unescape([]byte(tc.in))
+			if !errors.Is(err, ErrInvalidEscape) {
+				t.Fatalf("expected: %v, got: %v", ErrInvalidEscape, err)
+			}
+			if err.Error() != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, err)
+			}
+		})
+	}
+
+	for _, in := range []string{``, `"`, `abc`, `123`, `"abc`} {
+		if _, err := Unescape([]byte(in)); err == nil {
+			t.Fatalf("%q: expected: error, got: <nil>", in)
+		}
+	}
+}
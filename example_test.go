@@ -41,6 +41,39 @@ func ExampleScanner_Next() {
 	// }
 }
 
+func ExampleScanner_NextToken() {
+	input := `{"a": 1, "b": [true, "two", null]}`
+	sc := json.NewScanner(strings.NewReader(input))
+	for {
+		kind, tok := sc.NextToken()
+		switch kind {
+		case json.KindInvalid:
+			if err := sc.Error(); err != nil && err != io.EOF {
+				log.Fatal(err)
+			}
+			return
+		case json.KindString, json.KindNumber:
+			fmt.Printf("%v: %s\n", kind, tok)
+		case json.KindColon, json.KindComma:
+			// ignore punctuation.
+		default:
+			fmt.Printf("%v\n", kind)
+		}
+	}
+
+	// Output:
+	// object start
+	// string: "a"
+	// number: 1
+	// string: "b"
+	// array start
+	// true
+	// string: "two"
+	// null
+	// array end
+	// object end
+}
+
 func ExampleDecoder_Token() {
 	input := `{"a": 1,"b": 123.456, "c": [null]}`
 	dec := json.NewDecoder(strings.NewReader(input))
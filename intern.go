@@ -0,0 +1,63 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Limits on the keys interned by Scanner.KeyString, which bound the memory
+// the intern table may hold however many distinct keys the input contains.
+const (
+	maxInternedKeys   = 4096 // number of keys
+	maxInternedKeyLen = 128  // length of a single key in bytes
+)
+
+// SetInternKeys controls whether KeyString interns the strings it returns, so
+// that a key which recurs throughout the stream, such as "id" in a large array
+// of objects, allocates one string which all of its occurrences share. To
+// bound the size of the table, once it holds 4096 keys, or for keys longer
+// than 128 bytes, KeyString allocates a new string as Unescape does. Reset
+// empties the table.
+func (s *Scanner) SetInternKeys(v bool) {
+	s.internKeys = v
+}
+
+// KeyString returns the value of the string token tok, typically an object
+// key most recently returned by Next, unescaped as by Unescape. If
+// SetInternKeys is enabled, identical keys return the same string.
+func (s *Scanner) KeyString(tok []byte) (string, error) {
+	if !s.internKeys {
+		return Unescape(tok)
+	}
+	var key []byte
+	if bytes.IndexByte(tok, '\\') < 0 {
+		if len(tok) < 2 || tok[0] != '"' || tok[len(tok)-1] != '"' {
+			return "", fmt.Errorf("Unescape: expected string, got %s", describe(tok))
+		}
+		key = tok[1 : len(tok)-1]
+	} else {
+		buf, err := AppendUnescaped(s.keyBuf[:0], tok)
+		s.keyBuf = buf
+		if err != nil {
+			return "", err
+		}
+		key = buf
+	}
+	if k, ok := s.interned[string(key)]; ok {
+		return k, nil
+	}
+	k := string(key)
+	if len(k) <= maxInternedKeyLen && len(s.interned) < maxInternedKeys {
+		if s.interned == nil {
+			s.interned = make(map[string]string)
+		}
+		s.interned[k] = k
+	}
+	return k, nil
+}
+
+// SetInternKeys controls whether the keys of objects decoded into maps and
+// interface{} values are interned. See Scanner.SetInternKeys.
+func (d *Decoder) SetInternKeys(v bool) {
+	d.scanner.SetInternKeys(v)
+}
@@ -0,0 +1,77 @@
+package json
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestScannerKeyString(t *testing.T) {
+	sc := NewScanner(strings.NewReader(`[{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]`))
+	sc.SetInternKeys(true)
+	var keys []string
+	for tok := sc.Next(); len(tok) > 0; tok = sc.Next() {
+		if tok[0] != String || tok[1] != 'i' && tok[1] != 'n' {
+			continue
+		}
+		key, err := sc.KeyString(tok)
+		check(t, err)
+		keys = append(keys, key)
+	}
+	if got, want := strings.Join(keys, " "), "id name id name"; got != want {
+		t.Fatalf("expected: %q, got: %q", want, got)
+	}
+	for i := 0; i < 2; i++ {
+		if unsafe.StringData(keys[i]) != unsafe.StringData(keys[i+2]) {
+			t.Fatalf("expected %q to be interned", keys[i])
+		}
+	}
+
+	escaped, err := sc.KeyString([]byte(`"\u0069d"`))
+	check(t, err)
+	if unsafe.StringData(escaped) != unsafe.StringData(keys[0]) {
+		t.Fatalf("expected %q to be interned", escaped)
+	}
+
+	if _, err := sc.KeyString([]byte(`1`)); err == nil {
+		t.Fatalf("expected an error for a non string token")
+	}
+	if _, err := sc.KeyString([]byte(`"\x"`)); err == nil {
+		t.Fatalf("expected an error for an invalid escape")
+	}
+}
+
+func TestScannerKeyStringBounded(t *testing.T) {
+	sc := NewScanner(strings.NewReader(``))
+	sc.SetInternKeys(true)
+	for i := 0; i < 2*maxInternedKeys; i++ {
+		sc.KeyString([]byte(`"` + strconv.Itoa(i) + `"`))
+	}
+	long := `"` + strings.Repeat("x", maxInternedKeyLen+1) + `"`
+	sc.KeyString([]byte(long))
+	if len(sc.interned) != maxInternedKeys {
+		t.Fatalf("expected: %d interned keys, got: %d", maxInternedKeys, len(sc.interned))
+	}
+	if got, _ := sc.KeyString([]byte(`"99999"`)); got != "99999" {
+		t.Fatalf("expected: %q, got: %q", "99999", got)
+	}
+
+	sc.Reset(strings.NewReader(``))
+	if len(sc.interned) != 0 {
+		t.Fatalf("expected: an empty table after Reset, got: %d keys", len(sc.interned))
+	}
+	if !sc.internKeys {
+		t.Fatalf("expected: SetInternKeys to be retained by Reset")
+	}
+}
+
+func TestDecoderInternKeys(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`[{"id": 1}, {"id": 2}]`))
+	dec.SetInternKeys(true)
+	var rows []map[string]int
+	check(t, dec.Decode(&rows))
+	if len(rows) != 2 || rows[0]["id"] != 1 || rows[1]["id"] != 2 {
+		t.Fatalf("unexpected: %v", rows)
+	}
+}
@@ -0,0 +1,132 @@
+package json
+
+import (
+	"fmt"
+	"io"
+)
+
+// Array consumes the next value in the stream, which must be an array, and
+// calls fn with the bytes of each of its elements in turn, including any
+// whitespace inside the element. The element is only valid for the duration
+// of the call to fn. An empty array results in no calls to fn. On success the
+// scanner is left positioned after the closing bracket of the array.
+//
+// As with NextValue, the elements are checked for properly nested brackets,
+// but are not otherwise validated.
+// If fn returns an error, Array stops and returns that error.
+// At the end of the input stream, Array returns io.EOF.
+func (s *Scanner) Array(fn func(elem []byte) error) error {
+	tok := s.Next()
+	if len(tok) < 1 {
+		return s.tokenErr(io.EOF)
+	}
+	if tok[0] != ArrayStart {
+		return fmt.Errorf("Array: expected array, got %s at offset %d", describe(tok), s.br.pos())
+	}
+	return s.elements("Array", fn)
+}
+
+// elements consumes the rest of the array whose opening bracket was the last
+// token, calling fn with each of its elements as Array does. Errors are
+// prefixed with name.
+func (s *Scanner) elements(name string, fn func(elem []byte) error) error {
+	tok := s.Next()
+	if len(tok) < 1 {
+		return s.tokenErr(io.ErrUnexpectedEOF)
+	}
+	if tok[0] == ArrayEnd {
+		return nil
+	}
+	var elem []byte
+	for {
+		var err error
+		if elem, err = s.appendTokens(elem[:0], tok); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := fn(elem); err != nil {
+			return err
+		}
+		more, err := s.more(ArrayEnd)
+		if !more {
+			return err
+		}
+		if tok = s.Next(); len(tok) < 1 {
+			return s.tokenErr(io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+// Object consumes the next value in the stream, which must be an object, and
+// calls fn with the key and the bytes of the value of each of its members in
+// turn. The key excludes the surrounding quotes and any escape sequences are
+// left as is. The key and value are only valid for the duration of the call
+// to fn. An empty object results in no calls to fn. On success the scanner is
+// left positioned after the closing brace of the object.
+//
+// As with NextValue, the member values are checked for properly nested
+// brackets, but are not otherwise validated.
+// If fn returns an error, Object stops and returns that error.
+// At the end of the input stream, Object returns io.EOF.
+func (s *Scanner) Object(fn func(key, value []byte) error) error {
+	tok := s.Next()
+	if len(tok) < 1 {
+		return s.tokenErr(io.EOF)
+	}
+	if tok[0] != ObjectStart {
+		return fmt.Errorf("Object: expected object, got %s at offset %d", describe(tok), s.br.pos())
+	}
+	if tok = s.Next(); len(tok) < 1 {
+		return s.tokenErr(io.ErrUnexpectedEOF)
+	}
+	if tok[0] == ObjectEnd {
+		return nil
+	}
+	var key, value []byte
+	for {
+		if tok[0] != String {
+			return fmt.Errorf("Object: expected string key, got %s at offset %d", describe(tok), s.br.pos())
+		}
+		// the key is invalidated by the next token, copy it.
+		key = append(key[:0], tok[1:len(tok)-1]...)
+		if tok = s.Next(); len(tok) < 1 {
+			return s.tokenErr(io.ErrUnexpectedEOF)
+		}
+		if tok[0] != Colon {
+			return fmt.Errorf("Object: expected colon, got %s at offset %d", describe(tok), s.br.pos())
+		}
+		if tok = s.Next(); len(tok) < 1 {
+			return s.tokenErr(io.ErrUnexpectedEOF)
+		}
+		var err error
+		if value, err = s.appendTokens(value[:0], tok); err != nil {
+			return fmt.Errorf("Object: %w", err)
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+		more, err := s.more(ObjectEnd)
+		if !more {
+			return err
+		}
+		if tok = s.Next(); len(tok) < 1 {
+			return s.tokenErr(io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+// more consumes the token following an element of an array or a member of an
+// object, and reports whether another follows it: true for a comma, false
+// with a nil error for end, the closing bracket of the container.
+func (s *Scanner) more(end byte) (bool, error) {
+	tok := s.Next()
+	switch {
+	case len(tok) < 1:
+		return false, s.tokenErr(io.ErrUnexpectedEOF)
+	case tok[0] == Comma:
+		return true, nil
+	case tok[0] == end:
+		return false, nil
+	default:
+		return false, fmt.Errorf("expected comma or %s, got %s at offset %d", kinds[end], describe(tok), s.br.pos())
+	}
+}
@@ -0,0 +1,128 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScannerArray(t *testing.T) {
+	tests := []struct {
+		json string
+		want []string
+	}{
+		{json: `[]`},
+		{json: `[ ]`},
+		{json: "[\n]"},
+		{json: `[1]`, want: []string{`1`}},
+		{json: `[[],[1]]`, want: []string{`[]`, `[1]`}},
+		{json: `[{}, {"a": [ ]}, "x", null]`, want: []string{`{}`, `{"a": [ ]}`, `"x"`, `null`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json + ` "after"`)})
+			var got []string
+			err := sc.Array(func(elem []byte) error {
+				got = append(got, string(elem))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf("expected: %q, got: %q", tc.want, got)
+			}
+			if tok := string(sc.Next()); tok != `"after"` {
+				t.Fatalf("expected: %q, got: %q", `"after"`, tok)
+			}
+		})
+	}
+}
+
+func TestScannerObject(t *testing.T) {
+	tests := []struct {
+		json string
+		want []string
+	}{
+		{json: `{}`},
+		{json: `{ }`},
+		{json: "{\n}"},
+		{json: `{"a": 1}`, want: []string{`a`, `1`}},
+		{json: `{"a": {}, "b": []}`, want: []string{`a`, `{}`, `b`, `[]`}},
+		{json: `{"a\"b": {"c": []}, "d": [{}]}`, want: []string{`a\"b`, `{"c": []}`, `d`, `[{}]`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json + ` "after"`)})
+			var got []string
+			err := sc.Object(func(key, value []byte) error {
+				got = append(got, string(key), string(value))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf("expected: %q, got: %q", tc.want, got)
+			}
+			if tok := string(sc.Next()); tok != `"after"` {
+				t.Fatalf("expected: %q, got: %q", `"after"`, tok)
+			}
+		})
+	}
+}
+
+func TestScannerArrayNested(t *testing.T) {
+	// iterating the elements of each element of the outer array.
+	sc := NewScanner(strings.NewReader(`[[],[1],[]]`))
+	var outer, inner int
+	err := sc.Array(func(elem []byte) error {
+		outer++
+		return NewScanner(strings.NewReader(string(elem))).Array(func([]byte) error {
+			inner++
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outer != 3 || inner != 1 {
+		t.Fatalf("expected: 3 outer and 1 inner elements, got: %d, %d", outer, inner)
+	}
+}
+
+func TestScannerIteratorInvalid(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		json string
+		fn   func(*Scanner) error
+		err  error
+	}{
+		{json: ``, fn: func(sc *Scanner) error { return sc.Array(func([]byte) error { return nil }) }, err: io.EOF},
+		{json: `{}`, fn: func(sc *Scanner) error { return sc.Array(func([]byte) error { return nil }) }},
+		{json: `[`, fn: func(sc *Scanner) error { return sc.Array(func([]byte) error { return nil }) }, err: io.ErrUnexpectedEOF},
+		{json: `[1,]`, fn: func(sc *Scanner) error { return sc.Array(func([]byte) error { return nil }) }},
+		{json: `[1 2]`, fn: func(sc *Scanner) error { return sc.Array(func([]byte) error { return nil }) }},
+		{json: `[1, 2]`, fn: func(sc *Scanner) error { return sc.Array(func([]byte) error { return errStop }) }, err: errStop},
+		{json: `[]`, fn: func(sc *Scanner) error { return sc.Object(func(_, _ []byte) error { return nil }) }},
+		{json: `{`, fn: func(sc *Scanner) error { return sc.Object(func(_, _ []byte) error { return nil }) }, err: io.ErrUnexpectedEOF},
+		{json: `{"a"}`, fn: func(sc *Scanner) error { return sc.Object(func(_, _ []byte) error { return nil }) }},
+		{json: `{"a": 1,}`, fn: func(sc *Scanner) error { return sc.Object(func(_, _ []byte) error { return nil }) }},
+		{json: `{1: 1}`, fn: func(sc *Scanner) error { return sc.Object(func(_, _ []byte) error { return nil }) }},
+		{json: `{"a": 1`, fn: func(sc *Scanner) error { return sc.Object(func(_, _ []byte) error { return nil }) }, err: io.ErrUnexpectedEOF},
+		{json: `{"a": 1}`, fn: func(sc *Scanner) error { return sc.Object(func(_, _ []byte) error { return errStop }) }, err: errStop},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			err := tc.fn(NewScanner(strings.NewReader(tc.json)))
+			if err == nil || tc.err != nil && !errors.Is(err, tc.err) {
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// An NDJSONScanner reads newline delimited JSON, one value per line, from an
+// input stream. Each line is validated independently, so an invalid line is
+// reported without preventing the lines after it from being read.
+type NDJSONScanner struct {
+	r    *bufio.Reader
+	line []byte
+	n    int // number of lines read
+
+	sc   Scanner
+	docs []RawMessage
+}
+
+// NewNDJSONScanner returns a new NDJSONScanner for the io.Reader r.
+func NewNDJSONScanner(r io.Reader) *NDJSONScanner {
+	return &NDJSONScanner{r: bufio.NewReader(r)}
+}
+
+// A LineError records an invalid line of newline delimited JSON.
+type LineError struct {
+	Line int    // the line number, starting at 1
+	Text []byte // the content of the line, excluding the line ending
+	Err  error  // the reason the line is invalid
+}
+
+func (e *LineError) Error() string {
+	if len(e.Text) > maxDescribeLen {
+		return fmt.Sprintf("line %d: %v: %q... (truncated)", e.Line, e.Err, truncate(e.Text))
+	}
+	return fmt.Sprintf("line %d: %v: %q", e.Line, e.Err, e.Text)
+}
+
+func (e *LineError) Unwrap() error { return e.Err }
+
+// errMultipleValues is the LineError.Err for a line holding more than one
+// value.
+var errMultipleValues = errors.New("more than one value")
+
+// Next returns the value on the next non-blank line, excluding any
+// surrounding whitespace. The value is only valid until Next is called again.
+//
+// If the line does not hold exactly one valid JSON value, Next returns a
+// *LineError describing it; the NDJSONScanner remains usable and the
+// following call to Next continues with the next line.
+// At the end of the input stream, Next returns nil, io.EOF. Any other error
+// from the underlying reader is returned as is.
+func (s *NDJSONScanner) Next() (RawMessage, error) {
+	for {
+		if err := s.readLine(); err != nil {
+			return nil, err
+		}
+		s.sc = Scanner{br: byteReader{data: s.line}}
+		var err error
+		s.docs, err = s.sc.documents(s.line, s.docs[:0])
+		switch {
+		case err != nil:
+			return nil, &LineError{Line: s.n, Text: bytes.Clone(s.line), Err: err}
+		case len(s.docs) > 1:
+			return nil, &LineError{Line: s.n, Text: bytes.Clone(s.line), Err: errMultipleValues}
+		case len(s.docs) == 1:
+			return s.docs[0], nil
+		}
+		// a blank line.
+	}
+}
+
+// readLine reads the next line into s.line, excluding the line ending.
+func (s *NDJSONScanner) readLine() error {
+	s.line = s.line[:0]
+	for {
+		frag, err := s.r.ReadSlice('\n')
+		s.line = append(s.line, frag...)
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && len(s.line) > 0:
+			// the last line has no line ending.
+		case err != nil:
+			return err
+		}
+		s.n++
+		s.line = bytes.TrimSuffix(bytes.TrimSuffix(s.line, []byte("\n")), []byte("\r"))
+		return nil
+	}
+}
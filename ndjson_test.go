@@ -0,0 +1,90 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNDJSONScanner(t *testing.T) {
+	input := strings.Join([]string{
+		`{"a": 1}`,
+		``,
+		`  [1, 2]  `,
+		`{"a": `,
+		"\"x\"\r",
+		`1 2`,
+		`{"b": true}} `,
+		`null`,
+		`{"s": "` + strings.Repeat("y", 5000) + `"}`,
+		`tru`,
+	}, "\n")
+
+	type result struct {
+		value string
+		line  int // of the LineError, if non zero
+	}
+	want := []result{
+		{value: `{"a": 1}`},
+		{value: `[1, 2]`},
+		{line: 4},
+		{value: `"x"`},
+		{line: 6},
+		{line: 7},
+		{value: `null`},
+		{value: `{"s": "` + strings.Repeat("y", 5000) + `"}`},
+		{line: 10},
+	}
+
+	sc := NewNDJSONScanner(iotest.HalfReader(strings.NewReader(input)))
+	var lines = strings.Split(input, "\n")
+	for i, w := range want {
+		v, err := sc.Next()
+		if w.line == 0 {
+			if err != nil || string(v) != w.value {
+				t.Fatalf("%d: expected: %q, got: %q, %v", i, w.value, v, err)
+			}
+			continue
+		}
+		var lerr *LineError
+		if !errors.As(err, &lerr) {
+			t.Fatalf("%d: expected: *LineError, got: %q, %v", i, v, err)
+		}
+		if lerr.Line != w.line || string(lerr.Text) != lines[w.line-1] {
+			t.Fatalf("%d: expected: line %d %q, got: line %d %q", i, w.line, lines[w.line-1], lerr.Line, lerr.Text)
+		}
+	}
+	if v, err := sc.Next(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %q, %v", io.EOF, v, err)
+	}
+}
+
+func TestNDJSONScannerLineError(t *testing.T) {
+	sc := NewNDJSONScanner(strings.NewReader("[1,\n"))
+	_, err := sc.Next()
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+	if want := `line 1: expected value: unexpected EOF: "[1,"`; err.Error() != want {
+		t.Fatalf("expected: %q, got: %q", want, err)
+	}
+
+	sc = NewNDJSONScanner(strings.NewReader(`["` + strings.Repeat("z", 40)))
+	_, err = sc.Next()
+	if want := `line 1: expected value or array end: unexpected EOF: "[\"` + strings.Repeat("z", 30) + `"... (truncated)`; err == nil || err.Error() != want {
+		t.Fatalf("expected: %q, got: %v", want, err)
+	}
+}
+
+func TestNDJSONScannerReaderError(t *testing.T) {
+	errRead := errors.New("read failed")
+	sc := NewNDJSONScanner(io.MultiReader(strings.NewReader("1\n"), iotest.ErrReader(errRead)))
+	if v, err := sc.Next(); err != nil || string(v) != `1` {
+		t.Fatalf("expected: %q, got: %q, %v", `1`, v, err)
+	}
+	if _, err := sc.Next(); err != errRead {
+		t.Fatalf("expected: %v, got: %v", errRead, err)
+	}
+}
@@ -0,0 +1,312 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// ParseInt returns the value of the number token, as returned by Next, if it
+// is an integer: it has no fraction or exponent, and fits in an int64.
+// Otherwise ParseInt returns 0, false. Leading zeros are rejected, as they are
+// not valid JSON; underscores between digits, as permitted by
+// Scanner.SetAllowUnderscores, are skipped. -0 is 0. For larger integers, see
+// ParseBig.
+func ParseInt(token []byte) (int64, bool) {
+	neg := len(token) > 0 && token[0] == '-'
+	digits := token
+	if neg {
+		digits = token[1:]
+	}
+	if len(digits) < 1 || len(digits) > 1 && digits[0] == '0' {
+		return 0, false
+	}
+	var u uint64
+	for i, c := range digits {
+		if c == '_' && i > 0 && isDigit(digits[i-1]) && i+1 < len(digits) && isDigit(digits[i+1]) {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		if u > (math.MaxUint64-9)/10 {
+			return 0, false
+		}
+		u = u*10 + uint64(c-'0')
+	}
+	switch {
+	case neg && u <= -math.MinInt64:
+		return -int64(u), true
+	case !neg && u <= math.MaxInt64:
+		return int64(u), true
+	default:
+		return 0, false
+	}
+}
+
+// maxExactInt is the largest integer below which every integer is exactly
+// representable as a float64.
+const maxExactInt = 1 << 53
+
+// ParseFloat returns the value of the number token, as returned by Next, as a
+// float64. Integers which are exactly representable are converted directly,
+// other numbers with strconv.ParseFloat, without allocating. If the number is
+// out of range it is rounded to ±Inf, or to ±0 if too small, and as with
+// strconv.ParseFloat, an error wrapping strconv.ErrRange is returned for the
+// former. NaN, Infinity and -Infinity, as accepted by
+// Scanner.SetAllowNonFiniteNumbers, are converted to NaN, +Inf and -Inf.
+// Underscores between digits, as permitted by Scanner.SetAllowUnderscores,
+// are skipped. ParseFloat returns an error if token is not otherwise a valid
+// JSON number. To keep the value of a number which is out of range, see
+// ParseBig.
+func ParseFloat(token []byte) (float64, error) {
+	switch string(token) {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	if i, ok := ParseInt(token); ok && -maxExactInt <= i && i <= maxExactInt {
+		if i == 0 && token[0] == '-' {
+			return math.Copysign(0, -1), nil
+		}
+		return float64(i), nil
+	}
+	if _, ok := numberKind(token); !ok {
+		return 0, fmt.Errorf("ParseFloat: invalid number %q", token)
+	}
+	var buf [64]byte
+	f, err := strconv.ParseFloat(bytesToString(withoutUnderscores(buf[:], token)), 64)
+	if err != nil {
+		// the error must not retain the scanner's buffer.
+		err = &strconv.NumError{Func: "ParseFloat", Num: string(token), Err: err.(*strconv.NumError).Err}
+	}
+	return f, err
+}
+
+// ParseBig returns the value of the number token, as returned by Next, as a
+// big.Float, for numbers which are out of range for ParseInt and ParseFloat,
+// see IsBigNumber. The precision of the result is at least 64 bits, and
+// enough to hold every digit of the token, so that an integer such as
+// 123456789012345678901234567890 is exact, and 1e400 is not rounded to +Inf.
+// Infinity and -Infinity, as accepted by Scanner.SetAllowNonFiniteNumbers,
+// are converted to ±Inf. NaN, which a big.Float cannot represent, is an
+// error, as is a number whose exponent is beyond the range of a big.Float,
+// such as 1e99999999999, and a token which is not a valid JSON number.
+func ParseBig(token []byte) (*big.Float, error) {
+	switch string(token) {
+	case "Infinity":
+		return new(big.Float).SetInf(false), nil
+	case "-Infinity":
+		return new(big.Float).SetInf(true), nil
+	}
+	if _, ok := numberKind(token); !ok {
+		return nil, fmt.Errorf("ParseBig: invalid number %q", token)
+	}
+	// four bits per decimal digit is more than enough.
+	prec := max(4*uint(len(token)), 64)
+	f, _, err := big.ParseFloat(string(withoutUnderscores(nil, token)), 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("ParseBig: %w", err)
+	}
+	return f, nil
+}
+
+// IsBigNumber reports whether the number token, as returned by Next, is out
+// of range for the native types, so that only ParseBig preserves its value:
+// an integer which does not fit in an int64, such as 9223372036854775808, or
+// a number with a fraction or exponent which ParseFloat would round to ±Inf,
+// such as 1.8e308, or to ±0 although it is not zero, such as 1e-400.
+// A number which is in range but not exactly representable as a float64,
+// such as 0.1, is not big. Nor are non-finite and invalid numbers.
+func IsBigNumber(token []byte) bool {
+	integer, ok := numberKind(token)
+	switch {
+	case !ok:
+		return false
+	case integer:
+		_, ok := ParseInt(token)
+		return !ok
+	}
+	var buf [64]byte
+	f, err := strconv.ParseFloat(bytesToString(withoutUnderscores(buf[:], token)), 64)
+	return err != nil || f == 0 && !zeroDigits(token)
+}
+
+// withoutUnderscores returns the number token with any underscore digit
+// separators removed, copied into buf if it is long enough, or else into a
+// new slice. A token without underscores is returned as is.
+func withoutUnderscores(buf, token []byte) []byte {
+	if bytes.IndexByte(token, '_') < 0 {
+		return token
+	}
+	if len(buf) < len(token) {
+		buf = make([]byte, len(token))
+	}
+	n := 0
+	for _, c := range token {
+		if c != '_' {
+			buf[n] = c
+			n++
+		}
+	}
+	return buf[:n]
+}
+
+// isDigit reports whether c is a decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// zeroDigits reports whether every digit of the number token, before its
+// exponent, is zero.
+func zeroDigits(token []byte) bool {
+	for _, c := range token {
+		switch {
+		case c == 'e' || c == 'E':
+			return true
+		case c >= '1' && c <= '9':
+			return false
+		}
+	}
+	return true
+}
+
+// A NumberKind classifies a number token, see ClassifyNumber.
+type NumberKind uint8
+
+const (
+	InvalidNumber NumberKind = iota // not a valid JSON number
+	IntKind                         // an integer, with neither fraction nor exponent
+	FloatKind                       // a number with a fraction, an exponent, or both
+)
+
+// ClassifyNumber reports whether the number token, as returned by Next, is
+// written as an integer or as a floating point number, without parsing its
+// value or allocating. A number with an exponent, such as 1e5, is FloatKind
+// even if its value is integral, as it may not fit in an int64; an IntKind
+// number may not either, see ParseInt. NaN, Infinity and -Infinity, as
+// accepted by Scanner.SetAllowNonFiniteNumbers, are FloatKind. Anything else
+// is InvalidNumber.
+func ClassifyNumber(token []byte) NumberKind {
+	switch string(token) {
+	case "NaN", "Infinity", "-Infinity":
+		return FloatKind
+	}
+	switch integer, ok := numberKind(token); {
+	case !ok:
+		return InvalidNumber
+	case integer:
+		return IntKind
+	default:
+		return FloatKind
+	}
+}
+
+// numberKind reports whether token is a valid JSON number, as accepted by
+// parseNumber, and whether it is an integer, that is, it has neither a
+// fraction nor an exponent. Underscores are accepted between digits, except
+// after a leading zero, as parseNumber accepts them with SetAllowUnderscores
+// enabled.
+func numberKind(token []byte) (integer, ok bool) {
+	const (
+		begin = iota
+		leadingzero
+		anydigit1
+		decimal
+		anydigit2
+		exponent
+		expsign
+		anydigit3
+		underscore1
+		underscore2
+		underscore3
+	)
+	var state uint8 = begin
+	i := 0
+	if len(token) > 0 && token[0] == '-' {
+		i++
+	}
+	for ; i < len(token); i++ {
+		c := token[i]
+		digit := c >= '0' && c <= '9'
+		switch state {
+		case begin:
+			switch {
+			case c == '0':
+				state = leadingzero
+			case digit:
+				state = anydigit1
+			default:
+				return false, false
+			}
+		case anydigit1, leadingzero:
+			switch {
+			case digit && state == anydigit1:
+			case c == '_' && state == anydigit1:
+				state = underscore1
+			case c == '.':
+				state = decimal
+			case c == 'e' || c == 'E':
+				state = exponent
+			default:
+				return false, false
+			}
+		case decimal:
+			if !digit {
+				return false, false
+			}
+			state = anydigit2
+		case anydigit2:
+			switch {
+			case digit:
+			case c == '_':
+				state = underscore2
+			case c == 'e' || c == 'E':
+				state = exponent
+			default:
+				return false, false
+			}
+		case exponent:
+			if c == '+' || c == '-' {
+				state = expsign
+				break
+			}
+			fallthrough
+		case expsign, anydigit3:
+			if c == '_' && state == anydigit3 {
+				state = underscore3
+				break
+			}
+			if !digit {
+				return false, false
+			}
+			state = anydigit3
+		case underscore1, underscore2, underscore3:
+			// an underscore must be followed by a digit.
+			if !digit {
+				return false, false
+			}
+			switch state {
+			case underscore1:
+				state = anydigit1
+			case underscore2:
+				state = anydigit2
+			default:
+				state = anydigit3
+			}
+		}
+	}
+	switch state {
+	case leadingzero, anydigit1:
+		return true, true
+	case anydigit2, anydigit3:
+		return false, true
+	default:
+		return false, false
+	}
+}
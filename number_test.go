@@ -0,0 +1,234 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestParseInt(t *testing.T) {
+	valid := []struct {
+		in   string
+		want int64
+	}{
+		{in: `0`, want: 0},
+		{in: `-0`, want: 0},
+		{in: `7`, want: 7},
+		{in: `-42`, want: -42},
+		{in: `1234567890`, want: 1234567890},
+		{in: `9223372036854775807`, want: math.MaxInt64},
+		{in: `-9223372036854775808`, want: math.MinInt64},
+		{in: `1_000`, want: 1000},
+		{in: `-1_2_3`, want: -123},
+	}
+	for _, tc := range valid {
+		got, ok := ParseInt([]byte(tc.in))
+		if !ok || got != tc.want {
+			t.Fatalf("%s: expected: %v, true, got: %v, %v", tc.in, tc.want, got, ok)
+		}
+	}
+
+	invalid := []string{
+		``, `-`, `01`, `-01`, `00`, `+1`, `1.0`, `1e3`, `1E3`, `0.5`, `abc`,
+		`_1`, `1_`, `-_1`, `1__0`, `0_1`, `1_000.5`,
+		`9223372036854775808`, `-9223372036854775809`, `18446744073709551616`, `99999999999999999999`,
+	}
+	for _, in := range invalid {
+		if got, ok := ParseInt([]byte(in)); ok {
+			t.Fatalf("%s: expected: 0, false, got: %v, %v", in, got, ok)
+		}
+	}
+}
+
+func TestParseFloat(t *testing.T) {
+	valid := []string{
+		`0`, `-0`, `1`, `-1`, `0.5`, `-0.0`, `1.5e3`, `1E-3`, `2e+10`, `123456789012345678901234567890`,
+		`9007199254740993`, `4.9e-324`, `1.7976931348623157e308`, `1e-400`, `-1e-400`,
+	}
+	for _, in := range valid {
+		want, err := strconv.ParseFloat(in, 64)
+		check(t, err)
+		got, err := ParseFloat([]byte(in))
+		if err != nil || got != want || math.Signbit(got) != math.Signbit(want) {
+			t.Fatalf("%s: expected: %v, got: %v, %v", in, want, got, err)
+		}
+	}
+
+	for _, in := range []string{`1e400`, `-1e400`, `1.8e308`} {
+		got, err := ParseFloat([]byte(in))
+		if !errors.Is(err, strconv.ErrRange) || !math.IsInf(got, 0) {
+			t.Fatalf("%s: expected: ±Inf, %v, got: %v, %v", in, strconv.ErrRange, got, err)
+		}
+	}
+
+	if got, err := ParseFloat([]byte(`NaN`)); err != nil || !math.IsNaN(got) {
+		t.Fatalf("NaN: expected: NaN, got: %v, %v", got, err)
+	}
+	for in, sign := range map[string]int{`Infinity`: 1, `-Infinity`: -1} {
+		if got, err := ParseFloat([]byte(in)); err != nil || !math.IsInf(got, sign) {
+			t.Fatalf("%s: expected: %v, got: %v, %v", in, math.Inf(sign), got, err)
+		}
+	}
+
+	for in, want := range map[string]float64{`1_000`: 1000, `1_000.5`: 1000.5, `-1.2_5e1_0`: -1.25e10, `1_2345_6789_0123_4567_8901`: 123456789012345678901} {
+		if got, err := ParseFloat([]byte(in)); err != nil || got != want {
+			t.Fatalf("%s: expected: %v, got: %v, %v", in, want, got, err)
+		}
+	}
+	tok := []byte(`1_000.5`)
+	if n := testing.AllocsPerRun(100, func() { ParseFloat(tok) }); n != 0 {
+		t.Fatalf("expected: no allocations, got: %v", n)
+	}
+
+	invalid := []string{``, `-`, `01`, `1.`, `.5`, `1e`, `1e+`, `+1`, `inf`, `nan`, `-NaN`, `+Infinity`, `Inf`, `0x10`, `1.5e3x`, `1_`, `1__0`, `0_1`, `1_.5`, `1._5`, `1_e5`, `1e_5`}
+	for _, in := range invalid {
+		if got, err := ParseFloat([]byte(in)); err == nil {
+			t.Fatalf("%s: expected: error, got: %v", in, got)
+		}
+	}
+}
+
+func TestParseBig(t *testing.T) {
+	ints := []string{
+		`0`, `-42`, `9223372036854775807`, `9223372036854775808`, `-9223372036854775809`,
+		`18446744073709551616`, `123456789012345678901234567890`,
+	}
+	for _, in := range ints {
+		want, _ := new(big.Int).SetString(in, 10)
+		f, err := ParseBig([]byte(in))
+		check(t, err)
+		if got, acc := f.Int(nil); got.Cmp(want) != 0 || acc != big.Exact {
+			t.Fatalf("%s: expected: %v exactly, got: %v, %v", in, want, got, acc)
+		}
+	}
+
+	floats := []struct {
+		in   string
+		want string // formatted with %g
+	}{
+		{in: `0.5`, want: `0.5`},
+		{in: `1.7976931348623157e308`, want: `1.7976931348623157e+308`},
+		{in: `1.8e308`, want: `1.8e+308`},
+		{in: `1e400`, want: `1e+400`},
+		{in: `-1E400`, want: `-1e+400`},
+		{in: `4.9e-324`, want: `4.9e-324`},
+		{in: `1e-400`, want: `1e-400`},
+		{in: `3.14159265358979323846264338327950288`, want: `3.14159265358979323846264338327950288`},
+		{in: `Infinity`, want: `+Inf`},
+		{in: `-Infinity`, want: `-Inf`},
+		{in: `1_000.5`, want: `1000.5`},
+		{in: `1e4_00`, want: `1e+400`},
+	}
+	for _, tc := range floats {
+		f, err := ParseBig([]byte(tc.in))
+		check(t, err)
+		if got := fmt.Sprintf("%g", f); got != tc.want {
+			t.Fatalf("%s: expected: %s, got: %s", tc.in, tc.want, got)
+		}
+	}
+
+	for _, in := range []string{``, `-`, `01`, `1.`, `NaN`, `1_`, `0x10`, `1e99999999999`} {
+		if got, err := ParseBig([]byte(in)); err == nil {
+			t.Fatalf("%s: expected: an error, got: %v", in, got)
+		}
+	}
+}
+
+func TestIsBigNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{in: `0`},
+		{in: `-0`},
+		{in: `0.1`},
+		{in: `9223372036854775807`},
+		{in: `-9223372036854775808`},
+		{in: `9223372036854775808`, want: true},
+		{in: `-9223372036854775809`, want: true},
+		{in: `123456789012345678901234567890`, want: true},
+		{in: `9.3e18`},
+		{in: `1.7976931348623157e308`},
+		{in: `-1.7976931348623157e308`},
+		{in: `1.7976931348623159e308`, want: true},
+		{in: `1.8e308`, want: true},
+		{in: `1e400`, want: true},
+		{in: `-1e400`, want: true},
+		{in: `4.9e-324`},
+		{in: `2e-324`, want: true},
+		{in: `1e-400`, want: true},
+		{in: `0e-400`},
+		{in: `0.000e999`},
+		{in: `NaN`},
+		{in: `Infinity`},
+		{in: `01`},
+		{in: `1_000.5`},
+		{in: `1e4_00`, want: true},
+	}
+	for _, tc := range tests {
+		if got := IsBigNumber([]byte(tc.in)); got != tc.want {
+			t.Fatalf("%s: expected: %v, got: %v", tc.in, tc.want, got)
+		}
+	}
+}
+
+func TestClassifyNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want NumberKind
+	}{
+		{in: `0`, want: IntKind},
+		{in: `-0`, want: IntKind},
+		{in: `42`, want: IntKind},
+		{in: `-1234567890`, want: IntKind},
+		{in: `99999999999999999999`, want: IntKind},
+		{in: `0.5`, want: FloatKind},
+		{in: `-1.0`, want: FloatKind},
+		{in: `1e5`, want: FloatKind},
+		{in: `1E-3`, want: FloatKind},
+		{in: `2.5e+10`, want: FloatKind},
+		{in: `NaN`, want: FloatKind},
+		{in: `-Infinity`, want: FloatKind},
+		{in: ``, want: InvalidNumber},
+		{in: `-`, want: InvalidNumber},
+		{in: `01`, want: InvalidNumber},
+		{in: `1.`, want: InvalidNumber},
+		{in: `.5`, want: InvalidNumber},
+		{in: `1e`, want: InvalidNumber},
+		{in: `+1`, want: InvalidNumber},
+		{in: `1_000`, want: IntKind},
+		{in: `1_000.5`, want: FloatKind},
+		{in: `1_`, want: InvalidNumber},
+		{in: `0_1`, want: InvalidNumber},
+		{in: `true`, want: InvalidNumber},
+	}
+	for _, tc := range tests {
+		if got := ClassifyNumber([]byte(tc.in)); got != tc.want {
+			t.Fatalf("%q: expected: %v, got: %v", tc.in, tc.want, got)
+		}
+	}
+	if n := testing.AllocsPerRun(100, func() { ClassifyNumber([]byte(`-1234.5e6`)) }); n != 0 {
+		t.Fatalf("expected: no allocations, got: %v", n)
+	}
+}
+
+func BenchmarkClassifyNumber(b *testing.B) {
+	tok := []byte(`-12345.678e9`)
+	b.Run("ClassifyNumber", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ClassifyNumber(tok)
+		}
+	})
+	b.Run("strconv", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := strconv.ParseInt(string(tok), 10, 64); err != nil {
+				strconv.ParseFloat(string(tok), 64)
+			}
+		}
+	})
+}
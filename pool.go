@@ -0,0 +1,56 @@
+package json
+
+import (
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize is the largest buffer PutScanner returns to the pool;
+// a scanner whose buffer has grown beyond it, to hold an unusually large
+// token, is pooled without its buffer so that the memory may be reclaimed.
+const maxPooledBufferSize = 64 << 10
+
+var scannerPool = sync.Pool{
+	New: func() any { return new(Scanner) },
+}
+
+// GetScanner returns a Scanner for the io.Reader r, as NewScanner does, but
+// reuses a Scanner, and its buffer, previously released with PutScanner if
+// one is available. The Scanner has the default options, whatever options
+// were set on it before it was released.
+func GetScanner(r io.Reader) *Scanner {
+	s := scannerPool.Get().(*Scanner)
+	s.br.r = r
+	return s
+}
+
+// PutScanner releases s, which must not be used afterwards, to the pool used
+// by GetScanner. The tokens returned by s are no longer valid once it is
+// released, as its buffer may be handed on to another Scanner.
+func PutScanner(s *Scanner) {
+	s.release()
+	scannerPool.Put(s)
+}
+
+// release discards the scanner's state and options, and its references to
+// the reader and any key function, keeping its buffer, if it owns one of a
+// reasonable size, and the stacks which track the open containers.
+func (s *Scanner) release() {
+	buf := s.br.data[:0]
+	if s.br.r == nil || cap(buf) > maxPooledBufferSize {
+		// the buffer belongs to the caller of NewScannerBytes, or is too
+		// large to keep.
+		buf = nil
+	}
+	*s = Scanner{
+		br: byteReader{
+			data: buf,
+		},
+		containers: s.containers[:0],
+		keys:       s.keys,
+		valid: validator{
+			stack: s.valid.stack[:0],
+			path:  s.valid.path[:0],
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGetScanner(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		sc := GetScanner(strings.NewReader(`{"a": [1, true]} // done`))
+		got, err := scanAll(sc)
+		sc.SetAllowComments(true)
+		PutScanner(sc)
+		if want := `[{ "a" : [ 1 , true ] }]`; fmt.Sprint(got) != want {
+			t.Fatalf("expected: %s, got: %s", want, got)
+		}
+		// comments are not allowed by default.
+		if err == nil || err == io.EOF {
+			t.Fatalf("expected: an error for the comment, got: %v", err)
+		}
+	}
+}
+
+func TestScannerRelease(t *testing.T) {
+	sc := NewScanner(strings.NewReader(`[1, 2]`))
+	sc.SetValidate(true)
+	sc.SetKeyValidator(func(key []byte) error { return nil })
+	sc.Next()
+	buf := sc.br.data
+	sc.release()
+	if sc.br.r != nil || sc.validate || sc.valid.keyFn != nil || sc.depth != 0 {
+		t.Fatalf("expected: a cleared scanner, got: %+v", sc)
+	}
+	if len(sc.br.data) != 0 || cap(sc.br.data) != cap(buf) {
+		t.Fatalf("expected: the buffer to be kept")
+	}
+
+	// the caller's data is not retained.
+	sc = NewScannerBytes([]byte(`[1, 2]`))
+	sc.Next()
+	sc.release()
+	if sc.br.data != nil {
+		t.Fatalf("expected: no buffer, got: %q", sc.br.data)
+	}
+
+	// nor is an oversized buffer.
+	sc = NewScanner(bytes.NewReader([]byte(`"` + strings.Repeat("x", 2*maxPooledBufferSize) + `"`)))
+	sc.Next()
+	sc.release()
+	if sc.br.data != nil {
+		t.Fatalf("expected: no buffer, got: %d bytes", cap(sc.br.data))
+	}
+}
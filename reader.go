@@ -1,11 +1,22 @@
 package json
 
-import "io"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
 
 // A byteReader implements a sliding window over an io.Reader.
 type byteReader struct {
 	data   []byte
 	offset int
+	base   int64 // stream offset of data[0]
+	limit  int64 // maximum number of bytes to read from r, 0 for unlimited
+	pin    int64 // stream offset from which data is retained, if pinned
+	pinned bool
+	hold   int64 // stream offset from which data is retained, if held, see Scanner.setKey
+	held   bool
 	r      io.Reader
 	err    error
 }
@@ -21,50 +32,130 @@ func (b *byteReader) window() []byte {
 	return b.data[b.offset:]
 }
 
+// pos returns the stream offset of the start of the window.
+func (b *byteReader) pos() int64 {
+	return b.base + int64(b.offset)
+}
+
 // tuning constants for byteReader.extend.
 const (
 	newBufferSize = 4096
 	minReadSize   = newBufferSize >> 2
 )
 
+// maxEmptyReads is the number of consecutive reads which return neither data
+// nor an error that extend tolerates before failing with io.ErrNoProgress, as
+// bufio.Reader does.
+const maxEmptyReads = 100
+
 // extend extends the window with data from the underlying reader.
+// If the last read failed with a timeout, the read is retried.
 func (b *byteReader) extend() int {
 	if b.err != nil {
+		if !b.timedOut() {
+			return 0
+		}
+		// the reader may have recovered.
+		b.err = nil
+	}
+	if b.r == nil {
+		// the window holds all the data there is.
+		b.err = io.EOF
 		return 0
 	}
 
-	remaining := len(b.data) - b.offset
+	keep := b.offset // data before keep may be discarded
+	if b.pinned {
+		keep = min(keep, int(b.pin-b.base))
+	}
+	if b.held {
+		keep = min(keep, int(b.hold-b.base))
+	}
+	remaining := len(b.data) - keep
 	if remaining == 0 {
+		b.base += int64(keep)
 		b.data = b.data[:0]
-		b.offset = 0
+		b.offset -= keep
 	}
 	if cap(b.data)-len(b.data) >= minReadSize {
 		// nothing to do, enough space exists between len and cap.
 	} else if cap(b.data)-remaining >= minReadSize {
 		// buffer has enough space if we move the data to the front.
-		b.compact()
+		b.compact(keep)
 	} else {
 		// otherwise, we must allocate/extend a new buffer
-		b.grow()
+		b.grow(keep)
+	}
+	remaining = len(b.data)
+	end := cap(b.data)
+	if b.limit > 0 {
+		// read no more than one byte beyond the limit, enough to tell
+		// whether the stream exceeds it.
+		if allowed := b.limit + 1 - b.base; allowed < int64(end) {
+			end = int(allowed)
+		}
+	}
+	for i := 0; ; i++ {
+		n, err := b.r.Read(b.data[remaining:end])
+		// reduce length to the existing plus the data we read.
+		b.data = b.data[:remaining+n]
+		b.err = err
+		if b.limit > 0 && b.base+int64(len(b.data)) > b.limit {
+			b.data = b.data[:b.limit-b.base]
+			b.err = fmt.Errorf("%w: maximum %d bytes", ErrInputTooLarge, b.limit)
+			return len(b.data) - remaining
+		}
+		if n > 0 || err != nil {
+			return n
+		}
+		if i == maxEmptyReads {
+			b.err = io.ErrNoProgress
+			return 0
+		}
 	}
-	remaining += b.offset
-	n, err := b.r.Read(b.data[remaining:cap(b.data)])
-	// reduce length to the existing plus the data we read.
-	b.data = b.data[:remaining+n]
-	b.err = err
-	return n
 }
 
-// grow grows the buffer, moving the active data to the front.
-func (b *byteReader) grow() {
+// timedOut reports whether the last read failed with a timeout, such as that
+// of a net.Conn whose deadline has passed, from which a later read may
+// recover.
+func (b *byteReader) timedOut() bool {
+	var t interface{ Timeout() bool }
+	return b.err != nil && errors.As(b.err, &t) && t.Timeout()
+}
+
+// truncated reports whether the window may end part way through a token
+// which the stream continues, because the last read timed out, or the stream
+// exceeded the limit and was cut short at it.
+func (b *byteReader) truncated() bool {
+	return b.timedOut() || errors.Is(b.err, ErrInputTooLarge)
+}
+
+// grow grows the buffer, moving the data from keep on to the front.
+func (b *byteReader) grow(keep int) {
 	buf := make([]byte, max(cap(b.data)*2, newBufferSize))
-	copy(buf, b.data[b.offset:])
-	b.data = buf
-	b.offset = 0
+	n := copy(buf, b.data[keep:])
+	b.data = buf[:n]
+	b.base += int64(keep)
+	b.offset -= keep
 }
 
-// compact moves the active data to the front of the buffer.
-func (b *byteReader) compact() {
-	copy(b.data, b.data[b.offset:])
-	b.offset = 0
+// compact moves the data from keep on to the front of the buffer.
+func (b *byteReader) compact(keep int) {
+	n := copy(b.data, b.data[keep:])
+	b.data = b.data[:n]
+	b.base += int64(keep)
+	b.offset -= keep
+}
+
+// A contextReader is an io.Reader which fails once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(buf []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(buf)
 }
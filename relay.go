@@ -0,0 +1,67 @@
+package json
+
+import "io"
+
+// relayBufferSize is the number of bytes WriteTo buffers before writing them
+// to the io.Writer.
+const relayBufferSize = 4096
+
+// WriteTo implements io.WriterTo. It scans the rest of the stream as Next
+// does, writing each token to w along with the whitespace which precedes it,
+// so that w receives a byte for byte copy of the input, including any
+// comments and byte order mark skipped with SetAllowComments and SetAllowBOM.
+// The scanner thus serves as a validating pass-through when SetValidate is
+// enabled. WriteTo returns the number of bytes written and nil once the stream
+// is exhausted, or the first error encountered scanning the stream or writing
+// to w; the input up to the token which failed to scan has been written.
+func (s *Scanner) WriteTo(w io.Writer) (int64, error) {
+	var buf []byte
+	var written int64
+	flush := func() error {
+		n, err := w.Write(buf)
+		written += int64(n)
+		buf = buf[:0]
+		return err
+	}
+	if s.bom && s.br.pos() == 0 {
+		s.skipBOM()
+		buf = append(buf, s.br.data[:s.br.offset]...)
+	}
+	for {
+		buf = s.appendSpace(buf)
+		tok := s.Next()
+		buf = append(buf, tok...)
+		if len(tok) < 1 {
+			if err := flush(); err != nil {
+				return written, err
+			}
+			return written, s.tokenErr(nil)
+		}
+		if len(buf) >= relayBufferSize {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+}
+
+// appendSpace consumes any whitespace, and comments if SetAllowComments is
+// enabled, following the current token, appending them to dst.
+func (s *Scanner) appendSpace(dst []byte) []byte {
+	for {
+		dst = s.appendWhitespace(dst)
+		w := s.br.window()
+		if len(w) < 1 || w[0] != '/' || !s.comments {
+			return dst
+		}
+		start := s.br.pos()
+		if !s.skipComment() {
+			// leave the error to Next.
+			return dst
+		}
+		// skipComment releases the comment only once it is complete, so
+		// its bytes are still in the buffer, directly before the window.
+		n := int(s.br.pos() - start)
+		dst = append(dst, s.br.data[s.br.offset-n:s.br.offset]...)
+	}
+}
@@ -0,0 +1,79 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScannerWriteTo(t *testing.T) {
+	tests := []string{
+		``,
+		`   `,
+		`{"a": [1, 2.5, "three", true, false, null]}`,
+		"\n\t{ \"a\" :\r\n[ ]   }  \n\n",
+		"1 2\n3\n",
+		`["` + strings.Repeat("x", 10000) + `"]`,
+	}
+	for _, tc := range inputs {
+		data, err := io.ReadAll(fixture(t, tc.path))
+		check(t, err)
+		tests = append(tests, string(data))
+	}
+
+	for _, in := range tests {
+		for _, r := range []func(string) io.Reader{
+			func(s string) io.Reader { return strings.NewReader(s) },
+			func(s string) io.Reader { return &SmallReader{r: strings.NewReader(s)} },
+		} {
+			var out bytes.Buffer
+			sc := NewScanner(r(in))
+			sc.SetValidate(true)
+			n, err := sc.WriteTo(&out)
+			check(t, err)
+			if out.String() != in {
+				t.Fatalf("expected: %.40q, got: %.40q", in, out.String())
+			}
+			if n != int64(len(in)) {
+				t.Fatalf("expected: %d bytes, got: %d", len(in), n)
+			}
+		}
+	}
+
+	// comments and a byte order mark are relayed too.
+	in := "\xef\xbb\xbf// header\n{\"a\": /* one */ 1, // two\n \"b\": 2} // end"
+	var out bytes.Buffer
+	sc := NewScanner(iotest.OneByteReader(strings.NewReader(in)))
+	sc.SetAllowComments(true)
+	sc.SetAllowBOM(true)
+	_, err := sc.WriteTo(&out)
+	check(t, err)
+	if out.String() != in {
+		t.Fatalf("expected: %q, got: %q", in, out.String())
+	}
+
+	// the input before a malformed or invalid token is written.
+	out.Reset()
+	sc = NewScanner(strings.NewReader(`[1, 2 3]`))
+	sc.SetValidate(true)
+	if _, err := sc.WriteTo(&out); err == nil {
+		t.Fatalf("expected: an error")
+	}
+	if out.String() != `[1, 2 ` {
+		t.Fatalf("expected: %q, got: %q", `[1, 2 `, out.String())
+	}
+
+	errWrite := errors.New("write failed")
+	sc = NewScanner(strings.NewReader(`[1, 2]`))
+	if _, err := sc.WriteTo(failingWriter{errWrite}); err != errWrite {
+		t.Fatalf("expected: %v, got: %v", errWrite, err)
+	}
+}
+
+// failingWriter is an io.Writer whose writes fail with err.
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
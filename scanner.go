@@ -1,7 +1,12 @@
 package json
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
 	"io"
+	"unicode/utf8"
 )
 
 const (
@@ -28,10 +33,117 @@ func NewScanner(r io.Reader) *Scanner {
 	}
 }
 
+// NewScannerSize returns a new Scanner for the io.Reader r whose buffer is
+// initially size bytes, rather than the default of 4096, so that a large
+// document may be read in fewer, larger reads. The buffer still grows beyond
+// size, doubling each time, if a single token does not fit in it.
+func NewScannerSize(r io.Reader, size int) *Scanner {
+	return &Scanner{
+		br: byteReader{
+			data: make([]byte, 0, max(size, 0)),
+			r:    r,
+		},
+	}
+}
+
+// NewScannerContext returns a new Scanner for the io.Reader r which stops
+// reading once ctx is done. The context is checked before each read from r;
+// once it is done, the Scanner reads no further and Error reports ctx.Err().
+// A read already blocked in r is not interrupted; for that, r itself must
+// support cancellation, for example with a deadline on a net.Conn.
+func NewScannerContext(ctx context.Context, r io.Reader) *Scanner {
+	return NewScanner(&contextReader{ctx: ctx, r: r})
+}
+
+// NewScannerBytes returns a new Scanner for the in-memory JSON data. The
+// Scanner reads data in place, without copying it into a buffer of its own;
+// the tokens returned by Next are slices of data, so data must not be
+// modified while it is being scanned.
+func NewScannerBytes(data []byte) *Scanner {
+	return &Scanner{
+		br: byteReader{
+			data: data,
+		},
+	}
+}
+
+// Reset discards the scanner's state and switches it to reading from r, so
+// the scanner may be reused, for example from a sync.Pool, without
+// allocating a new buffer. Options set with the Set methods are retained.
+// Tokens returned before Reset must not be used after it.
+func (s *Scanner) Reset(r io.Reader) {
+	valid := s.valid
+	interned := s.interned
+	clear(interned)
+	buf := s.br.data[:0]
+	if s.br.r == nil {
+		// the buffer belongs to the caller of NewScannerBytes.
+		buf = nil
+	}
+	*s = Scanner{
+		br: byteReader{
+			data:  buf,
+			limit: s.br.limit,
+			r:     r,
+		},
+		containers:   s.containers[:0],
+		keys:         s.keys,
+		maxDepth:     s.maxDepth,
+		maxTokenSize: s.maxTokenSize,
+		eofToken:     s.eofToken,
+		underscores:  s.underscores,
+		strictUTF8:   s.strictUTF8,
+		comments:     s.comments,
+		nonFinite:    s.nonFinite,
+		bom:          s.bom,
+		internKeys:   s.internKeys,
+		validate:     s.validate,
+		interned:     interned,
+		keyBuf:       s.keyBuf[:0],
+		valid: validator{
+			allowed: valid.allowed,
+			stack:   valid.stack[:0],
+			keyFn:   valid.keyFn,
+			path:    valid.path[:0],
+
+			trailingCommas: valid.trailingCommas,
+			dupKeys:        valid.dupKeys,
+			keys:           valid.keys,
+			trackPath:      valid.trackPath,
+		},
+	}
+}
+
 // Scanner implements a JSON scanner as defined in RFC 7159.
 type Scanner struct {
-	br     byteReader
-	offset int
+	br         byteReader
+	offset     int
+	depth      int      // number of open objects and arrays
+	containers stack    // open containers, true for objects, see InObject
+	keys       [][]byte // the current key of each open container, see CurrentKey
+	keyPos     int64    // stream offset of the innermost current key, if still buffered
+	keyLen     int      // length of that key, 0 if it has been copied to keys
+	keyNext    bool     // the next string is an object key
+
+	maxDepth     int // maximum depth, 0 for unlimited, see SetMaxDepth
+	maxTokenSize int // maximum token length, 0 for unlimited, see SetMaxTokenSize
+
+	eofToken    bool // report the end of the stream as KindEOF
+	eofSent     bool // KindEOF has been returned
+	ended       bool // the token before the current one ended a top-level value, see Done
+	underscores bool // allow _ digit separators in numbers
+	strictUTF8  bool // report invalid UTF-8 outside strings as ErrInvalidUTF8
+	comments    bool // skip // and /* */ comments as whitespace
+	nonFinite   bool // accept NaN, Infinity and -Infinity as numbers
+	bom         bool // skip a UTF-8 byte order mark at the start of the stream
+	internKeys  bool // intern the strings returned by KeyString
+	validate    bool // validate the structure of the tokens returned by Next
+
+	interned map[string]string // see SetInternKeys
+	keyBuf   []byte            // scratch space for unescaping keys
+
+	valid validator // structural validation, see Discard and SetValidate
+	err   error     // a structural error, see SetValidate
 }
 
 var whitespace = [256]bool{
@@ -59,9 +171,23 @@ var whitespace = [256]bool{
 //	n JSON null
 //	" A string, possibly containing backslash escaped entites.
 //	-, 0-9 A number
+//
+// If SetValidate is enabled, Next also checks that the tokens form well
+// formed JSON values; see SetValidate.
 func (s *Scanner) Next() []byte {
+	if s.err != nil {
+		return nil
+	}
+	if s.offset > 0 && s.depth == 0 {
+		s.ended = s.DocumentEnd()
+	}
 	s.br.release(s.offset)
+	s.offset = 0
+	if s.bom && s.br.pos() == 0 {
+		s.skipBOM()
+	}
 	w := s.br.window()
+scan:
 	for {
 		for pos, c := range w {
 			// strip any leading whitespace.
@@ -72,11 +198,42 @@ func (s *Scanner) Next() []byte {
 			// simple case
 			switch c {
 			case ObjectStart, ObjectEnd, Colon, Comma, ArrayStart, ArrayEnd:
-				s.offset = pos + 1
-				return w[pos:s.offset]
+				s.br.release(pos)
+				s.offset = 1
+				switch c {
+				case ObjectStart, ArrayStart:
+					s.depth++
+					if s.maxDepth > 0 && s.depth > s.maxDepth {
+						s.offset = 0
+						s.err = fmt.Errorf("%w: maximum %d at offset %d", ErrMaxDepthExceeded, s.maxDepth, s.br.pos())
+						return nil
+					}
+					s.open(c == ObjectStart)
+				case ObjectEnd, ArrayEnd:
+					if s.depth > 0 {
+						s.depth--
+						s.close()
+					}
+					s.keyNext = false
+				case Comma:
+					s.keyNext = s.InObject()
+				case Colon:
+					s.keyNext = false
+				}
+				if s.validate {
+					return s.validateNext(w[pos : pos+1])
+				}
+				return w[pos : pos+1]
 			}
 
 			s.br.release(pos)
+			if c == '/' && s.comments {
+				if !s.skipComment() {
+					return nil
+				}
+				w = s.br.window()
+				continue scan
+			}
 			switch c {
 			case True:
 				s.offset = s.validateToken("true")
@@ -86,11 +243,28 @@ func (s *Scanner) Next() []byte {
 				s.offset = s.validateToken("null")
 			case String:
 				if s.parseString() < 2 {
-					return nil
+					s.offset = 0
+				} else if s.keyNext {
+					s.keyNext = false
+					s.setKey(s.offset)
 				}
 			default:
-				// ensure the number is correct.
-				s.offset = s.parseNumber(c)
+				if s.nonFinite {
+					s.offset = s.parseNonFinite()
+				}
+				if s.offset == 0 {
+					// ensure the number is correct.
+					s.offset = s.parseNumber(c)
+				}
+			}
+			if s.maxTokenSize > 0 && s.offset > s.maxTokenSize {
+				s.tokenTooLarge()
+			}
+			if s.offset == 0 && s.err == nil {
+				s.syntaxError()
+			}
+			if s.validate {
+				return s.validateNext(s.br.window()[:s.offset])
 			}
 			return s.br.window()[:s.offset]
 		}
@@ -101,61 +275,583 @@ func (s *Scanner) Next() []byte {
 		// refill buffer
 		if s.br.extend() == 0 {
 			// eof
+			if s.validate {
+				return s.validateNext(nil)
+			}
 			return nil
 		}
 		w = s.br.window()
 	}
 }
 
+// SetMaxDepth limits the nesting depth of objects and arrays to n. Once an
+// object or array would be opened at a depth greater than n, Next returns a
+// zero length []byte slice and Error reports an error wrapping
+// ErrMaxDepthExceeded. With n of 0, the default, the depth is unlimited.
+func (s *Scanner) SetMaxDepth(n int) {
+	s.maxDepth = n
+}
+
+// SetMaxTotalBytes limits the number of bytes the scanner reads from the
+// underlying io.Reader to n, as a guard against input of unbounded size. Once
+// the stream proves to be longer than n bytes, the scanner stops reading;
+// Next returns the tokens within the first n bytes, except for a number or
+// literal at the very end, which the limit may have cut short, then a zero
+// length []byte slice, and Error reports an error wrapping
+// ErrInputTooLarge. A stream of exactly n bytes is permitted. With n of 0,
+// the default, the number of bytes is unlimited. The limit does not apply to
+// a Scanner created by NewScannerBytes.
+func (s *Scanner) SetMaxTotalBytes(n int64) {
+	s.br.limit = n
+}
+
+// SetMaxTokenSize limits the length of a single token, such as a string or
+// a number, to n bytes, which bounds the size of the scanner's buffer. Once
+// a token longer than n is encountered, the scanner stops reading, Next
+// returns a zero length []byte slice, and Error reports an error wrapping
+// ErrTokenTooLarge. A comment, as permitted by SetAllowComments, is limited
+// to n bytes likewise. With n of 0, the default, the length is unlimited.
+func (s *Scanner) SetMaxTokenSize(n int) {
+	s.maxTokenSize = n
+}
+
+// DocumentEnd reports whether the token most recently returned by Next
+// completed a top-level value, that is, whether it is the last token of a
+// document in a stream of concatenated or newline delimited documents, such
+// as {}{}{} or NDJSON. Only the nesting of brackets is considered; use
+// SetValidate to also check the structure of each document.
+func (s *Scanner) DocumentEnd() bool {
+	if s.offset < 1 || s.depth > 0 {
+		return false
+	}
+	switch s.br.window()[0] {
+	case ObjectStart, ArrayStart, Colon, Comma:
+		return false
+	default:
+		return true
+	}
+}
+
+// Buffered returns the bytes which the scanner has read from the underlying
+// io.Reader but not yet consumed, those following the token most recently
+// returned by Next. Once Next has completed a document, as reported by
+// DocumentEnd, the data which follows it in the stream is Buffered followed
+// by whatever remains to be read from the io.Reader, as with
+// bufio.Reader.Buffered.
+// The slice is only valid until the next call to Next.
+func (s *Scanner) Buffered() []byte {
+	return s.br.window()[s.offset:]
+}
+
+// Done checks that the token most recently returned by Next completed a
+// top-level value, as reported by DocumentEnd, and that nothing but
+// whitespace, and comments if SetAllowComments is enabled, follows it in the
+// stream, reading the rest of the stream to do so. Done may also be called
+// once Next has returned no token at the end of the stream, in which case it
+// checks the last token Next did return. Done returns nil if the stream ends
+// cleanly, an error wrapping ErrTrailingData if anything else follows the
+// value, io.ErrUnexpectedEOF if the stream ended before the value was
+// complete, or the error which stopped the scanner.
+func (s *Scanner) Done() error {
+	if s.err != nil {
+		return s.err
+	}
+	ended := s.DocumentEnd()
+	if s.offset == 0 {
+		// Next returned no token, so consider the last one it did return.
+		ended = s.ended && s.depth == 0
+	}
+	switch {
+	case ended:
+	case s.offset == 0 && s.br.err != nil:
+		// the stream ended before a value was complete.
+		return s.tokenErr(io.ErrUnexpectedEOF)
+	default:
+		return fmt.Errorf("Done: expected the end of a value at offset %d", s.br.pos()+int64(s.offset))
+	}
+	s.br.release(s.offset)
+	s.offset = 0
+	for {
+		w := s.br.window()
+		pos := 0
+		for pos < len(w) && whitespace[w[pos]] {
+			pos++
+		}
+		s.br.release(pos)
+		switch {
+		case pos < len(w) && w[pos] == '/' && s.comments:
+			if !s.skipComment() {
+				return s.tokenErr(nil)
+			}
+		case pos < len(w):
+			return fmt.Errorf("Done: %w at offset %d", ErrTrailingData, s.br.pos())
+		case s.br.extend() == 0:
+			return s.tokenErr(nil)
+		}
+	}
+}
+
+// SetAllowComments controls whether // line comments and /* */ block
+// comments, as found in JSONC files such as tsconfig.json, are skipped as if
+// they were whitespace. A line comment may run to the end of the stream; an
+// unterminated block comment is an error, reported by Error. Comments are not
+// permitted by RFC 8259, this mode is disabled by default.
+func (s *Scanner) SetAllowComments(v bool) {
+	s.comments = v
+}
+
+// SetAllowBOM controls whether a UTF-8 byte order mark, EF BB BF, at the very
+// start of the stream, as written by some Windows tools, is skipped. A byte
+// order mark anywhere else is an error. RFC 8259 forbids senders from
+// writing one, so this mode is disabled by default.
+func (s *Scanner) SetAllowBOM(v bool) {
+	s.bom = v
+}
+
+// utf8BOM is the UTF-8 encoding of U+FEFF, the byte order mark.
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// skipBOM consumes the byte order mark at the start of the window, if there
+// is one, reading more input if the window is shorter than one.
+func (s *Scanner) skipBOM() {
+	for len(s.br.window()) < len(utf8BOM) && bytes.HasPrefix(utf8BOM, s.br.window()) {
+		if s.br.extend() == 0 {
+			return
+		}
+	}
+	if bytes.HasPrefix(s.br.window(), utf8BOM) {
+		s.br.release(len(utf8BOM))
+	}
+}
+
+// skipComment consumes the comment at the start of the window, reporting
+// whether it is a complete comment. If the window does not start with a
+// comment, or the comment is unterminated, the reason is recorded in s.err.
+// If the underlying reader fails part way through the comment, the window is
+// left as is.
+func (s *Scanner) skipComment() bool {
+	for len(s.br.window()) < 2 {
+		if s.br.extend() == 0 {
+			if s.br.err == io.EOF {
+				// a lone /.
+				s.syntaxError()
+			}
+			return false
+		}
+	}
+	var end []byte
+	switch s.br.window()[1] {
+	case '/':
+		end = []byte("\n")
+	case '*':
+		end = []byte("*/")
+	default:
+		s.syntaxError()
+		return false
+	}
+	offset := 2
+	for {
+		w := s.br.window()
+		if i := bytes.Index(w[offset:], end); i >= 0 {
+			s.br.release(offset + i + len(end))
+			return true
+		}
+		if s.maxTokenSize > 0 && len(w) > s.maxTokenSize {
+			// the comment is buffered in full, so it counts as a token.
+			s.tokenTooLarge()
+			return false
+		}
+		// the end may straddle the next read.
+		offset = max(offset, len(w)-len(end)+1)
+		if s.br.extend() == 0 {
+			switch {
+			case s.br.err != io.EOF:
+				return false
+			case end[0] == '\n':
+				// the comment runs to the end of the stream.
+				s.br.release(len(s.br.window()))
+				return true
+			default:
+				s.err = fmt.Errorf("unterminated comment at offset %d", s.br.pos())
+				return false
+			}
+		}
+	}
+}
+
+// identifier holds the bytes which may not immediately follow a literal, lest
+// a bare word like trueee be split into true and ee.
+var identifier = func() (t [256]bool) {
+	for c := '0'; c <= '9'; c++ {
+		t[c] = true
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		t[c] = true
+		t[c-'a'+'A'] = true
+	}
+	t['_'] = true
+	return t
+}()
+
+// NextInto copies the next lexical token in the stream into dst, growing it
+// if needed, and returns the resulting slice. Unlike the result of Next, the
+// token remains valid after further calls to Next; dst may be reused for the
+// next token once the caller is done with it.
+// At the end of the input stream, NextInto returns nil, io.EOF. If an error
+// has occurred, NextInto returns nil and the error.
+func (s *Scanner) NextInto(dst []byte) ([]byte, error) {
+	tok := s.Next()
+	if len(tok) < 1 {
+		return nil, s.tokenErr(io.EOF)
+	}
+	return append(dst[:0], tok...), nil
+}
+
 func (s *Scanner) validateToken(expected string) int {
+	n := len(expected)
 	for {
 		w := s.br.window()
-		n := len(expected)
-		if len(w) >= n {
-			if string(w[:n]) != expected {
-				// doesn't match
+		if len(w) >= n && string(w[:n]) != expected {
+			// doesn't match
+			return 0
+		}
+		if len(w) > n {
+			if identifier[w[n]] {
+				// the literal runs into another word.
 				return 0
 			}
 			return n
 		}
 		// not enough data is left, we need to extend
 		if s.br.extend() == 0 {
-			// eof
+			// eof, which is fine directly after the literal, unless
+			// the read may yet be retried.
+			if len(w) == n && !s.br.truncated() {
+				return n
+			}
 			return 0
 		}
 	}
 }
 
-// parseString returns the length of the string token
-// located at the start of the window or 0 if there is no closing
-// " before the end of the byteReader.
+// SetAllowNonFiniteNumbers controls whether the literals NaN, Infinity and
+// -Infinity, as written by Python's json.dumps with allow_nan, are accepted as
+// number tokens. ParseFloat converts them to the corresponding float64
+// values. They are not permitted by RFC 8259, this mode is disabled by
+// default.
+func (s *Scanner) SetAllowNonFiniteNumbers(v bool) {
+	s.nonFinite = v
+}
+
+// parseNonFinite returns the length of the NaN, Infinity or -Infinity literal
+// located at the start of the window, or 0 if there is none.
+func (s *Scanner) parseNonFinite() int {
+	w := s.br.window()
+	for len(w) < 2 && s.br.extend() > 0 {
+		w = s.br.window()
+	}
+	switch {
+	case w[0] == 'N':
+		return s.validateToken("NaN")
+	case w[0] == 'I':
+		return s.validateToken("Infinity")
+	case len(w) > 1 && w[0] == '-' && w[1] == 'I':
+		return s.validateToken("-Infinity")
+	default:
+		return 0
+	}
+}
+
+// parseString returns the length of the string token located at the start
+// of the window, 0 if there is no closing " before the end of the
+// byteReader, or invalidString if the string contains an unescaped control
+// character, an invalid escape sequence, or invalid UTF-8, in which case the
+// reason is recorded in s.err.
 func (s *Scanner) parseString() int {
-	escaped := false
+	var st stringScan
 	w := s.br.window()[1:]
 	offset := 0
 	for {
-		for _, c := range w {
-			offset++
+		n, done, err := st.scan(w, offset)
+		switch {
+		case err != nil:
+			return s.invalidString(err, 1+n)
+		case done:
+			s.offset = n + 2
+			return s.offset
+		}
+		offset = n
+		// need more data from the pipe, at least the closing quote.
+		if s.maxTokenSize > 0 && offset+2 > s.maxTokenSize {
+			return s.tokenTooLarge()
+		}
+		if s.br.extend() == 0 {
+			// EOF.
+			return 0
+		}
+		w = s.br.window()[1:]
+	}
+}
+
+// A stringScan holds the state of a scan of the content of a string, so that
+// the scan may resume where it stopped once more of the string is read.
+type stringScan struct {
+	state      uint8
+	r          rune // the value of the \u escape so far
+	low        bool // the \u escape must be a low surrogate
+	start      int  // offset of the \ which began the current escape
+	quote, esc int  // offsets of the next " and \, see nextByte
+}
+
+// stringScan states.
+const (
+	plain   = iota
+	escaped // following \
+	hex1    // following \u
+	hex2
+	hex3
+	hex4
+	surrogate  // following \uD800 to \uDBFF
+	surrogateU // following a high surrogate and \
+)
+
+// scan scans w[offset:], the content of a string following its opening
+// quote, returning the offset of the closing quote, with done set, or else
+// of the first byte which cannot be scanned until more of the string is
+// read. If the string is invalid, scan returns the reason, and the offset of
+// the byte or escape sequence at fault.
+func (st *stringScan) scan(w []byte, offset int) (n int, done bool, err error) {
+	for offset < len(w) {
+		if st.state == plain {
+			// fast path: skip the run of plain text which precedes the
+			// next quote or backslash, rather than stepping through it
+			// byte by byte. Anything else is left to the loop below.
+			st.quote = nextByte(w, '"', st.quote, offset, len(w))
+			st.esc = nextByte(w, '\\', st.esc, offset, st.quote)
+			offset = skipPlain(w, offset, st.esc)
+			if offset == len(w) {
+				break
+			}
+		}
+		c := w[offset]
+		switch st.state {
+		case plain:
 			switch {
-			case escaped:
-				escaped = false
 			case c == '"':
 				// finished
-				s.offset = offset + 1
-				return s.offset
+				return offset, true, nil
 			case c == '\\':
-				escaped = true
+				st.state, st.start = escaped, offset
+			case c < 0x20:
+				return offset, false, ErrControlCharacter
+			case c >= utf8.RuneSelf:
+				if !utf8.FullRune(w[offset:]) {
+					// wait for the rest of the rune.
+					return offset, false, nil
+				}
+				r, size := utf8.DecodeRune(w[offset:])
+				if r == utf8.RuneError && size == 1 {
+					return offset, false, ErrInvalidUTF8
+				}
+				offset += size
+				continue
+			}
+		case escaped:
+			switch c {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				st.state = plain
+			case 'u':
+				st.state = hex1
+			default:
+				return offset - 1, false, ErrInvalidEscape
+			}
+		case surrogate:
+			if c != '\\' {
+				return offset - 6, false, errUnpairedSurrogate
+			}
+			st.state = surrogateU
+		case surrogateU:
+			if c != 'u' {
+				return offset - 7, false, errUnpairedSurrogate
+			}
+			st.state, st.low = hex1, true
+		default:
+			if !isHex(c) {
+				return offset - int(st.state-hex1) - 2, false, ErrInvalidEscape
+			}
+			st.r = st.r<<4 | hexValue(c)
+			if st.state < hex4 {
+				st.state++
+				break
+			}
+			switch r := st.r; {
+			case st.low && (r < 0xdc00 || r > 0xdfff):
+				// report the high surrogate which lacks a pair.
+				return offset - 11, false, errUnpairedSurrogate
+			case !st.low && r >= 0xdc00 && r <= 0xdfff:
+				return offset - 5, false, errUnpairedSurrogate
+			case !st.low && r >= 0xd800 && r < 0xdc00:
+				st.state = surrogate
+			default:
+				st.state, st.low = plain, false
+			}
+			st.r = 0
+		}
+		offset++
+	}
+	return offset, false, nil
+}
+
+// complete returns the offset up to which the content the scan has reached,
+// offset, holds only complete characters and escape sequences, with the two
+// halves of an escaped UTF-16 surrogate pair counted as one sequence.
+func (st *stringScan) complete(offset int) int {
+	if st.state == plain {
+		return offset
+	}
+	return st.start
+}
+
+// shift adjusts the scan's offsets once the first n bytes of the content
+// have been released from the window.
+func (st *stringScan) shift(n int) {
+	st.start -= n
+	st.quote, st.esc = 0, 0
+}
+
+// skipPlain returns the offset of the first byte in w[offset:end] which is a
+// control character or a part of an invalid or incomplete UTF-8 sequence, or
+// end if there is none.
+func skipPlain(w []byte, offset, end int) int {
+	for offset < end {
+		if end-offset >= 8 {
+			// check a word at a time for a control character or a byte
+			// of a multibyte rune.
+			x := binary.LittleEndian.Uint64(w[offset:])
+			if (x-0x2020202020202020|x)&0x8080808080808080 == 0 {
+				offset += 8
+				continue
+			}
+		}
+		switch c := w[offset]; {
+		case c < 0x20:
+			return offset
+		case c < utf8.RuneSelf:
+			offset++
+		default:
+			r, size := utf8.DecodeRune(w[offset:end])
+			if r == utf8.RuneError && size == 1 {
+				return offset
+			}
+			offset += size
+		}
+	}
+	return offset
+}
+
+// nextByte returns the offset of the first c in w[offset:limit], or limit if
+// there is none. prev is the result of an earlier call for the same c with a
+// smaller offset or limit; the search resumes from there, so that successive
+// calls scan no byte of w more than once.
+func nextByte(w []byte, c byte, prev, offset, limit int) int {
+	if prev >= offset && prev < limit && w[prev] == c {
+		return prev
+	}
+	from := max(prev, offset)
+	if i := bytes.IndexByte(w[from:limit], c); i >= 0 {
+		return from + i
+	}
+	return limit
+}
+
+// tokenTooLarge records an ErrTokenTooLarge error for the token at the start
+// of the window, and returns 0, to be returned as the token's length.
+func (s *Scanner) tokenTooLarge() int {
+	s.offset = 0
+	s.err = fmt.Errorf("%w: maximum %d bytes at offset %d", ErrTokenTooLarge, s.maxTokenSize, s.br.pos())
+	return 0
+}
+
+// invalidString is returned by parseString for an invalid string.
+const invalidString = -2
+
+// invalidString records err, found at offset within the window, which holds
+// a string or the rest of one, and returns invalidString.
+func (s *Scanner) invalidString(err error, offset int) int {
+	pos := s.br.pos() + int64(offset)
+	s.err = &SyntaxError{
+		Offset: pos,
+		Byte:   s.br.window()[offset],
+		err:    fmt.Errorf("%w in string at offset %d", err, pos),
+	}
+	return invalidString
+}
+
+// syntaxError records a SyntaxError in s.err for the malformed token at the
+// start of the window. If the token is valid, but truncated by the end of the
+// stream, io.ErrUnexpectedEOF is recorded instead.
+func (s *Scanner) syntaxError() {
+	w := s.br.window()
+	if s.br.err == io.EOF && partialToken(w) {
+		s.err = io.ErrUnexpectedEOF
+		return
+	}
+	if s.br.err != nil && partialToken(w) {
+		// the reader failed part way through a token, Error reports why.
+		return
+	}
+	e := &SyntaxError{Offset: s.br.pos(), Byte: w[0]}
+	if s.strictUTF8 && w[0] >= utf8.RuneSelf && !s.validRune() {
+		e.err = fmt.Errorf("%w at offset %d", ErrInvalidUTF8, e.Offset)
+	} else {
+		e.err = fmt.Errorf("%s at offset %d", DescribeToken(KindInvalid, s.invalidToken()), e.Offset)
+	}
+	s.err = e
+}
+
+// delimiter holds the bytes which end a malformed token, see invalidToken.
+var delimiter = [256]bool{
+	' ': true, '\t': true, '\r': true, '\n': true,
+	ObjectStart: true, ObjectEnd: true, ArrayStart: true, ArrayEnd: true,
+	Colon: true, Comma: true, String: true,
+}
+
+// invalidToken returns the malformed token at the start of the window, the
+// bytes up to the next whitespace or punctuation, reading more input if need
+// be, so that its description does not depend on how the input was read. At
+// most maxDescribeLen+1 bytes are returned, enough for DescribeToken to tell
+// that it must truncate them.
+func (s *Scanner) invalidToken() []byte {
+	for {
+		w := s.br.window()
+		for i := 1; i < len(w); i++ {
+			if delimiter[w[i]] || i > maxDescribeLen {
+				return w[:i]
 			}
 		}
-		// need more data from the pipe
 		if s.br.extend() == 0 {
-			// EOF.
-			return 0
+			return s.br.window()
 		}
-		w = s.br.window()[offset+1:]
 	}
 }
 
+// errUnpairedSurrogate is recorded for a \u escape of a UTF-16 surrogate
+// which is not part of a high, low surrogate pair.
+var errUnpairedSurrogate = fmt.Errorf("%w: unpaired surrogate", ErrInvalidEscape)
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'f'
+}
+
+// hexValue returns the value of the hexadecimal digit c.
+func hexValue(c byte) rune {
+	if c <= '9' {
+		return rune(c - '0')
+	}
+	return rune(c | 0x20 - 'a' + 10)
+}
+
 func (s *Scanner) parseNumber(c byte) int {
 	const (
 		begin = iota
@@ -166,6 +862,9 @@ func (s *Scanner) parseNumber(c byte) int {
 		exponent
 		expsign
 		anydigit3
+		underscore1
+		underscore2
+		underscore3
 	)
 
 	offset := 0
@@ -195,6 +894,10 @@ func (s *Scanner) parseNumber(c byte) int {
 					// stay in this state
 					break
 				}
+				if elem == '_' && s.underscores {
+					state = underscore1
+					break
+				}
 				fallthrough
 			case leadingzero:
 				if elem == '.' {
@@ -205,7 +908,7 @@ func (s *Scanner) parseNumber(c byte) int {
 					state = exponent
 					break
 				}
-				return offset // finished.
+				return numberEnd(offset, elem)
 			case decimal:
 				if elem >= '0' && elem <= '9' {
 					state = anydigit2
@@ -217,11 +920,15 @@ func (s *Scanner) parseNumber(c byte) int {
 				if elem >= '0' && elem <= '9' {
 					break
 				}
+				if elem == '_' && s.underscores {
+					state = underscore2
+					break
+				}
 				if elem == 'e' || elem == 'E' {
 					state = exponent
 					break
 				}
-				return offset // finished.
+				return numberEnd(offset, elem)
 			case exponent:
 				if elem == '+' || elem == '-' {
 					state = expsign
@@ -236,17 +943,44 @@ func (s *Scanner) parseNumber(c byte) int {
 				// error
 				return 0
 			case anydigit3:
+				if elem == '_' && s.underscores {
+					state = underscore3
+					break
+				}
+				if elem < '0' || elem > '9' {
+					return numberEnd(offset, elem)
+				}
+			case underscore1:
+				// an underscore must be followed by a digit.
 				if elem < '0' || elem > '9' {
-					return offset
+					return 0
 				}
+				state = anydigit1
+			case underscore2:
+				if elem < '0' || elem > '9' {
+					return 0
+				}
+				state = anydigit2
+			case underscore3:
+				if elem < '0' || elem > '9' {
+					return 0
+				}
+				state = anydigit3
 			}
 			offset++
 		}
 
 		// need more data from the pipe
+		if s.maxTokenSize > 0 && offset > s.maxTokenSize {
+			return s.tokenTooLarge()
+		}
 		if s.br.extend() == 0 {
 			// end of the item. However, not necessarily an error. Make
-			// sure we are in a state that allows ending the number.
+			// sure we are in a state that allows ending the number, and
+			// that no more digits can follow.
+			if s.br.truncated() {
+				return 0
+			}
 			switch state {
 			case leadingzero, anydigit1, anydigit2, anydigit3:
 				return offset
@@ -259,6 +993,47 @@ func (s *Scanner) parseNumber(c byte) int {
 	}
 }
 
+// numberEnd returns offset, the length of the number which is ended by next,
+// the byte following it, or 0 if next is a digit which runs on from the
+// number, as the 1 of 01 does, lest it be split into two numbers.
+func numberEnd(offset int, next byte) int {
+	if next >= '0' && next <= '9' {
+		return 0
+	}
+	return offset
+}
+
+// SetAllowUnderscores controls whether number tokens may contain underscores
+// as digit group separators, as in 1_000_000. An underscore must appear
+// between two digits; it may not start or end the number, nor be adjacent to
+// another underscore, a decimal point, or an exponent.
+// Underscores are not permitted by RFC 8259, this mode is disabled by default.
+func (s *Scanner) SetAllowUnderscores(v bool) {
+	s.underscores = v
+}
+
+// SetStrictUTF8 controls whether invalid UTF-8 encountered between tokens, as
+// opposed to inside a string, is reported as ErrInvalidUTF8. Such bytes are
+// never valid JSON; without this mode they are reported as an invalid token.
+func (s *Scanner) SetStrictUTF8(v bool) {
+	s.strictUTF8 = v
+}
+
 // Error returns the first error encountered.
-// When underlying reader is exhausted, Error returns io.EOF.
-func (s *Scanner) Error() error { return s.br.err }
+// When underlying reader is exhausted, Error returns io.EOF, or
+// io.ErrUnexpectedEOF if it ends part way through a token. If the input holds
+// a malformed token, Error returns a *SyntaxError. If a read times out, as one
+// from a net.Conn whose deadline has passed does, Next returns no token and
+// Error reports the timeout, but the next call to Next retries the read,
+// resuming the scan at the token which was interrupted. A reader which
+// repeatedly returns no data and no error is reported as io.ErrNoProgress.
+// Any other error returned by the underlying reader is reported as is, after
+// all the tokens buffered before it have been returned by Next. If
+// SetValidate is enabled, a structural error takes precedence over the
+// reader's.
+func (s *Scanner) Error() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.br.err
+}
@@ -1,9 +1,17 @@
 package json
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"math"
+	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 type SmallReader struct {
@@ -78,12 +86,348 @@ func TestScannerNext(t *testing.T) {
 	}
 }
 
+func TestScannerReaderError(t *testing.T) {
+	tests := []struct {
+		in     string
+		tokens []string
+	}{
+		{in: ``, tokens: nil},
+		{in: `{"a": 1`, tokens: []string{`{`, `"a"`, `:`, `1`}},
+		{in: `[1, "a", true, 23`, tokens: []string{`[`, `1`, `,`, `"a"`, `,`, `true`, `,`, `23`}},
+		{in: `[null, false] `, tokens: []string{`[`, `null`, `,`, `false`, `]`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			r := io.MultiReader(strings.NewReader(tc.in), iotest.ErrReader(io.ErrClosedPipe))
+			scanner := NewScanner(&SmallReader{r: r})
+			for n, want := range tc.tokens {
+				got := scanner.Next()
+				if string(got) != want {
+					t.Fatalf("%v: expected: %v, got: %v", n+1, want, string(got))
+				}
+			}
+			last := scanner.Next()
+			if len(last) > 0 {
+				t.Fatalf("expected: %q, got: %q", "", string(last))
+			}
+			if err := scanner.Error(); err != io.ErrClosedPipe {
+				t.Fatalf("expected: %v, got: %v", io.ErrClosedPipe, err)
+			}
+		})
+	}
+}
+
+// scriptReader returns the results of a scripted sequence of reads: data, or
+// an error if the read's data is empty.
+type scriptReader []struct {
+	data string
+	err  error
+}
+
+func (r *scriptReader) Read(buf []byte) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	read := (*r)[0]
+	*r = (*r)[1:]
+	return copy(buf, read.data), read.err
+}
+
+func TestScannerEmptyReads(t *testing.T) {
+	// a few reads with neither data nor an error are retried.
+	r := scriptReader{{data: `[1, 2`}, {}, {}, {data: `3, "a`}, {}, {data: `b"]`}}
+	sc := NewScanner(&r)
+	var got []string
+	for tok := sc.Next(); len(tok) > 0; tok = sc.Next() {
+		got = append(got, string(tok))
+	}
+	if want := `[[ 1 , 23 , "ab" ]]`; fmt.Sprint(got) != want {
+		t.Fatalf("expected: %s, got: %s", want, got)
+	}
+	if err := sc.Error(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+
+	// but not indefinitely.
+	empty := make(scriptReader, 1000)
+	empty[0].data = `[1`
+	sc = NewScanner(&empty)
+	for len(sc.Next()) > 0 {
+	}
+	if err := sc.Error(); err != io.ErrNoProgress {
+		t.Fatalf("expected: %v, got: %v", io.ErrNoProgress, err)
+	}
+}
+
+func TestScannerTimeout(t *testing.T) {
+	timeout := struct {
+		data string
+		err  error
+	}{err: os.ErrDeadlineExceeded}
+	r := scriptReader{
+		{data: `[1, 2`}, timeout,
+		{data: `3, tr`}, timeout, timeout,
+		{data: `ue, "a\`}, timeout,
+		{data: `"b", // a com`}, timeout,
+		{data: `ment` + "\n" + ` null, /`}, timeout,
+		{data: `* another *`}, timeout,
+		{data: `/ false]`},
+	}
+	sc := NewScanner(&r)
+	sc.SetAllowComments(true)
+	sc.SetValidate(true)
+	var got []string
+	timeouts := 0
+	for {
+		tok := sc.Next()
+		if len(tok) > 0 {
+			got = append(got, string(tok))
+			continue
+		}
+		if !errors.Is(sc.Error(), os.ErrDeadlineExceeded) {
+			break
+		}
+		timeouts++
+	}
+	if want := `[[ 1 , 23 , true , "a\"b" , null , false ]]`; fmt.Sprint(got) != want {
+		t.Fatalf("expected: %s, got: %s", want, got)
+	}
+	if timeouts != 7 {
+		t.Fatalf("expected: %d timeouts, got: %d", 7, timeouts)
+	}
+	if err := sc.Error(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+}
+
+// scanAll returns the tokens Next returns from sc, and the error it stops
+// with.
+func scanAll(sc *Scanner) ([]string, error) {
+	var toks []string
+	for tok := sc.Next(); len(tok) > 0; tok = sc.Next() {
+		toks = append(toks, string(tok))
+	}
+	return toks, sc.Error()
+}
+
+// TestScannerOneByteReads checks that reading the input a byte at a time,
+// so that every token straddles many reads, produces exactly the tokens and
+// error that scanning it in one piece does.
+func TestScannerOneByteReads(t *testing.T) {
+	tests := []string{
+		`true`, `false`, `null`, `[true,false,null]`, `{"a":true}`,
+		`tru`, `fals`, `nul`, `truex`, `nulll`, `[t]`, `[nul]`, `[fals,1]`,
+		`""`, `"abc"`, `"\"\\\/\b\f\n\r\t"`, `"é😀"`, "\"café \xf0\x9f\x98\x80\"",
+		`"\u00`, `"\uD83D"`, `"\uD83DA"`, `"\x"`, "\"a\x01\"", "\"\xff\"", `"abc`,
+		`0`, `-0`, `1`, `-12`, `0.5`, `-1.25e+10`, `1E-3`, `2e5`, `123456789.123456789e-123`,
+		`01`, `1.`, `-`, `.5`, `1e`, `1e+`, `[1e5]`, `[-0.0e-0]`, `[1 2]`, `12 34`,
+		`{"a": {"b": [1, [2, {"c": []}]], "d": {}}, "e": "f"}`,
+		" \t\r\n[ 1 , \"x\" ]\n",
+		`{"a":1}{"b":2}[3]"four"5`,
+		`[NaN, -Infinity, Infinity]`, `[Na]`, `[1_000, 2_0.5_1]`, `/* a */ [1, // b` + "\n" + ` 2] // c`, `/* d`, `/x`,
+	}
+	for _, tc := range inputs {
+		data, err := io.ReadAll(fixture(t, tc.path))
+		check(t, err)
+		tests = append(tests, string(data))
+	}
+
+	for _, in := range tests {
+		for _, options := range []func(*Scanner){
+			func(*Scanner) {},
+			func(sc *Scanner) {
+				sc.SetAllowComments(true)
+				sc.SetAllowNonFiniteNumbers(true)
+				sc.SetAllowUnderscores(true)
+				sc.SetValidate(true)
+			},
+		} {
+			testOneByteReads(t, in, options)
+		}
+	}
+}
+
+func testOneByteReads(t *testing.T, in string, options func(*Scanner)) {
+	t.Helper()
+	sc := NewScannerBytes([]byte(in))
+	options(sc)
+	want, wantErr := scanAll(sc)
+	for name, r := range map[string]io.Reader{
+		"onebyte": iotest.OneByteReader(strings.NewReader(in)),
+		"dataerr": iotest.DataErrReader(iotest.OneByteReader(strings.NewReader(in))),
+		"small":   &SmallReader{r: strings.NewReader(in)},
+	} {
+		sc := NewScanner(r)
+		options(sc)
+		got, err := scanAll(sc)
+		if len(got) != len(want) {
+			t.Fatalf("%s %.40q: expected: %d tokens, got: %d", name, in, len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s %.40q: token %d: expected: %q, got: %q", name, in, i+1, want[i], got[i])
+			}
+		}
+		if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			t.Fatalf("%s %.40q: expected: %v, got: %v", name, in, wantErr, err)
+		}
+	}
+}
+
 func TestParseString(t *testing.T) {
 	testParseString(t, `""`, `""`)
 	testParseString(t, `"" `, `""`)
 	testParseString(t, `"\""`, `"\""`)
-	testParseString(t, `"\\\\\\\\\6"`, `"\\\\\\\\\6"`)
-	testParseString(t, `"\6"`, `"\6"`)
+	testParseString(t, `"\\\\\\\\\n"`, `"\\\\\\\\\n"`)
+	testParseString(t, `"\/\b\f\n\r\t\u00e9\uD83D\uDE00"`, `"\/\b\f\n\r\t\u00e9\uD83D\uDE00"`)
+	testParseString(t, "\"caf\u00e9 \xe2\x82\xac \xf0\x9f\x98\x80\"", "\"caf\u00e9 \xe2\x82\xac \xf0\x9f\x98\x80\"")
+	testParseString(t, "\"\x7f\"", "\"\x7f\"")
+}
+
+func TestParseStringInvalid(t *testing.T) {
+	tests := []struct {
+		json string
+		err  error
+		pos  int // offset of the offending byte
+	}{
+		{json: `"\6"`, err: ErrInvalidEscape, pos: 1},
+		{json: `"abc\\\x"`, err: ErrInvalidEscape, pos: 6},
+		{json: `"\u"`, err: ErrInvalidEscape, pos: 1},
+		{json: `"\u12"`, err: ErrInvalidEscape, pos: 1},
+		{json: `"ab\u12G4"`, err: ErrInvalidEscape, pos: 3},
+		{json: `"\U1234"`, err: ErrInvalidEscape, pos: 1},
+		{json: `"\uD83D"`, err: errUnpairedSurrogate, pos: 1},
+		{json: `"\uD83Dx"`, err: errUnpairedSurrogate, pos: 1},
+		{json: `"a\uD83D\n"`, err: errUnpairedSurrogate, pos: 2},
+		{json: `"\uD83D\uD83D"`, err: errUnpairedSurrogate, pos: 1},
+		{json: `"\udbffA"`, err: errUnpairedSurrogate, pos: 1},
+		{json: `"\uDE00"`, err: errUnpairedSurrogate, pos: 1},
+		{json: `"ab\uDFFF\uDE00"`, err: errUnpairedSurrogate, pos: 3},
+		{json: `"\uD83D\uDE0G"`, err: ErrInvalidEscape, pos: 7},
+		{json: "\"a\x00\"", err: ErrControlCharacter, pos: 2},
+		{json: "\"line\nbreak\"", err: ErrControlCharacter, pos: 5},
+		{json: "\"tab\t\"", err: ErrControlCharacter, pos: 4},
+		{json: "\"\x1f\"", err: ErrControlCharacter, pos: 1},
+		{json: "\"\xff\"", err: ErrInvalidUTF8, pos: 1},
+		{json: "\"ab\xe2\x82\"", err: ErrInvalidUTF8, pos: 3},
+		{json: "\"\xc0\xaf\"", err: ErrInvalidUTF8, pos: 1},
+		{json: "\"\xed\xa0\x80\"", err: ErrInvalidUTF8, pos: 1},
+	}
+
+	for _, tc := range tests {
+		for _, r := range []func(string) io.Reader{
+			func(s string) io.Reader { return strings.NewReader(s) },
+			func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		} {
+			t.Run(tc.json, func(t *testing.T) {
+				scanner := NewScanner(r(`[` + tc.json + `]`))
+				scanner.Next()
+				if tok := scanner.Next(); len(tok) > 0 {
+					t.Fatalf("expected: no token, got: %q", tok)
+				}
+				err := scanner.Error()
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("expected: %v, got: %v", tc.err, err)
+				}
+				if want := fmt.Sprintf("%v in string at offset %d", tc.err, tc.pos+1); err.Error() != want {
+					t.Fatalf("expected: %q, got: %q", want, err)
+				}
+			})
+		}
+	}
+
+	// an escape at the end of the input is a truncated string.
+	for _, json := range []string{`"\`, `"\u`, `"\u00`, `"\u00e9`, "\"\xe2\x82", `"\uD83D`, `"\uD83D\`, `"\uD83D\uDE`} {
+		scanner := NewScanner(iotest.OneByteReader(strings.NewReader(json)))
+		if tok := scanner.Next(); len(tok) > 0 {
+			t.Fatalf("%q: expected: no token, got: %q", json, tok)
+		}
+		if err := scanner.Error(); err != io.ErrUnexpectedEOF {
+			t.Fatalf("%q: expected: %v, got: %v", json, io.ErrUnexpectedEOF, err)
+		}
+	}
+}
+
+func TestParseStringBackslashBoundary(t *testing.T) {
+	tests := []struct {
+		json string
+		want string // the token, empty if the string is invalid or truncated
+	}{
+		{json: `"a\\\\b"`, want: `"a\\\\b"`},
+		{json: `"a\\"`, want: `"a\\"`},
+		{json: `"\\\\\\"`, want: `"\\\\\\"`},
+		{json: `"\\\""`, want: `"\\\""`},
+		{json: `"\"\\"`, want: `"\"\\"`},
+		{json: `"\\\u00e9\\"`, want: `"\\\u00e9\\"`},
+		{json: `"\\\ud83d\ude00\\"`, want: `"\\\ud83d\ude00\\"`},
+		{json: `"\uD83D\\"`},
+		{json: `"\uD83D\uDBFF"`},
+		{json: `"\\\"`},
+		{json: `"\\\\\"`},
+		{json: `"\\\x"`},
+	}
+
+	for _, tc := range tests {
+		// split the input at every position, so that each backslash is
+		// the last byte before the scanner must extend its window.
+		for i := 1; i < len(tc.json); i++ {
+			for name, r := range map[string]io.Reader{
+				"split":   io.MultiReader(strings.NewReader(tc.json[:i]), strings.NewReader(tc.json[i:]+` 1`)),
+				"onebyte": iotest.OneByteReader(strings.NewReader(tc.json + ` 1`)),
+			} {
+				sc := NewScanner(r)
+				if tok := sc.Next(); string(tok) != tc.want {
+					t.Fatalf("%s at %d: %s: expected: %q, got: %q", name, i, tc.json, tc.want, tok)
+				}
+				if tc.want == "" {
+					continue
+				}
+				if tok := sc.Next(); string(tok) != `1` {
+					t.Fatalf("%s at %d: %s: expected: %q, got: %q", name, i, tc.json, `1`, tok)
+				}
+			}
+		}
+	}
+}
+
+func TestParseStringLong(t *testing.T) {
+	// place each kind of byte which ends the run of plain text at every
+	// offset within and across the words which are checked at once.
+	plain := strings.Repeat("abcdefgh", 4)
+	for i := 0; i <= len(plain); i++ {
+		for _, tc := range []struct {
+			s   string
+			err error
+		}{
+			{s: `\"`},
+			{s: `\\`},
+			{s: `\u00e9`},
+			{s: "\u00e9"},
+			{s: "\xf0\x9f\x98\x80"},
+			{s: "\x01", err: ErrControlCharacter},
+			{s: "\x7f"},
+			{s: "\xff", err: ErrInvalidUTF8},
+			{s: "\xe2\x82", err: ErrInvalidUTF8},
+		} {
+			json := `"` + plain[:i] + tc.s + plain[i:] + `"`
+			for _, sc := range []*Scanner{
+				NewScannerBytes([]byte(json)),
+				NewScanner(&SmallReader{r: strings.NewReader(json)}),
+			} {
+				tok := sc.Next()
+				if tc.err != nil {
+					if err := sc.Error(); !errors.Is(err, tc.err) {
+						t.Fatalf("%q: expected: %v, got: %v", json, tc.err, err)
+					}
+					continue
+				}
+				if string(tok) != json {
+					t.Fatalf("expected: %q, got: %q, %v", json, tok, sc.Error())
+				}
+			}
+		}
+	}
 }
 
 func testParseString(t *testing.T, json, want string) {
@@ -113,6 +457,67 @@ func TestParseNumber(t *testing.T) {
 	testParseNumber(t, `-1234567.891011121314`)
 }
 
+// invalidNumbers are numbers which RFC 8259 forbids, and which seed
+// FuzzScannerNumber.
+var invalidNumbers = []string{`01`, `00`, `-01`, `007`, `1.`, `.5`, `-`, `1e`, `1e+`, `1.e5`, `+1`, `-.5`}
+
+func TestParseNumberInvalid(t *testing.T) {
+	for _, tc := range invalidNumbers {
+		for _, in := range []string{tc, "[" + tc + "]", tc + " ", "[" + tc + ",1]"} {
+			for _, sc := range []*Scanner{
+				NewScannerBytes([]byte(in)),
+				NewScanner(iotest.OneByteReader(strings.NewReader(in))),
+			} {
+				for tok := sc.Next(); len(tok) > 0; tok = sc.Next() {
+					if kinds[tok[0]] == KindNumber {
+						t.Fatalf("%q: expected: an error, got: %q", in, tok)
+					}
+				}
+				err := sc.Error()
+				var serr *SyntaxError
+				if !errors.As(err, &serr) && err != io.ErrUnexpectedEOF {
+					t.Fatalf("%q: expected: a syntax error, got: %v", in, err)
+				}
+			}
+		}
+	}
+}
+
+// FuzzScannerNumber checks that the scanner accepts exactly the numbers which
+// numberKind, the grammar of RFC 8259 with underscores between digits, does,
+// those without underscores unless they are allowed, and never splits one in
+// two.
+func FuzzScannerNumber(f *testing.F) {
+	for _, tc := range invalidNumbers {
+		f.Add(tc, false)
+	}
+	for _, tc := range []string{`0`, `-0`, `1`, `-12`, `0.5`, `1.5e3`, `1E-3`, `2e+10`, `12a`, `0x1`, `1_0`} {
+		f.Add(tc, false)
+		f.Add(tc, true)
+	}
+	for _, tc := range []string{`1_000.5`, `1e1_0`, `0_1`, `1__0`, `1_`, `1._5`} {
+		f.Add(tc, true)
+	}
+	f.Fuzz(func(t *testing.T, in string, underscores bool) {
+		sc := NewScannerBytes([]byte(in))
+		sc.SetAllowUnderscores(underscores)
+		tok := sc.Next()
+		_, valid := numberKind([]byte(in))
+		valid = valid && (underscores || !strings.Contains(in, "_"))
+		switch {
+		case valid && string(tok) != in:
+			t.Fatalf("%q: expected: the number, got: %q, %v", in, tok, sc.Error())
+		case !valid && len(tok) > 0 && string(tok) == in && kinds[in[0]] == KindNumber:
+			t.Fatalf("%q: expected: an error, got: %q", in, tok)
+		}
+		if len(tok) > 0 && kinds[tok[0]] == KindNumber {
+			if end := int(sc.br.pos()) + len(tok); end < len(in) && in[end] >= '0' && in[end] <= '9' {
+				t.Fatalf("%q: expected: an error, got: %q split from the number", in, tok)
+			}
+		}
+	})
+}
+
 func testParseNumber(t *testing.T, tc string) {
 	t.Helper()
 	r := strings.NewReader(tc)
@@ -203,3 +608,872 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestScannerUnderscores(t *testing.T) {
+	valid := []string{`1_000`, `1_000.5`, `1_000_000`, `-1_0`, `1.000_5`, `1e1_0`, `1.5E+1_0`, `0.1_2`}
+	for _, tc := range valid {
+		t.Run(tc, func(t *testing.T) {
+			scanner := NewScanner(&SmallReader{r: strings.NewReader("[" + tc + "]")})
+			scanner.SetAllowUnderscores(true)
+			for _, want := range []string{`[`, tc, `]`} {
+				got := scanner.Next()
+				if string(got) != want {
+					t.Fatalf("expected: %q, got: %q", want, got)
+				}
+				if want != tc {
+					continue
+				}
+				// the number functions skip the underscores.
+				want, err := strconv.ParseFloat(strings.ReplaceAll(tc, "_", ""), 64)
+				check(t, err)
+				if f, err := ParseFloat(got); err != nil || f != want {
+					t.Fatalf("expected: %v, got: %v, %v", want, f, err)
+				}
+			}
+		})
+	}
+
+	invalid := []string{`_1`, `1_`, `1__0`, `1_.5`, `1._5`, `1_e5`, `1e_5`, `1e+_5`, `-_1`, `1.5_`}
+	for _, tc := range invalid {
+		t.Run(tc, func(t *testing.T) {
+			for _, in := range []string{tc, "[" + tc + "]"} {
+				scanner := NewScanner(&SmallReader{r: strings.NewReader(in)})
+				scanner.SetAllowUnderscores(true)
+				for {
+					tok := scanner.Next()
+					if len(tok) < 1 {
+						break
+					}
+					if string(tok) != `[` && string(tok) != `]` {
+						t.Fatalf("%s: expected error, got: %q", in, tok)
+					}
+				}
+			}
+		})
+	}
+
+	scanner := NewScanner(strings.NewReader(`1_000`))
+	if got := scanner.Next(); string(got) != `1` {
+		t.Fatalf("expected: %q, got: %q", `1`, got)
+	}
+}
+
+func TestScannerLiteralAdjacency(t *testing.T) {
+	valid := []struct {
+		in     string
+		tokens []string
+	}{
+		{in: `true`, tokens: []string{`true`}},
+		{in: `false `, tokens: []string{`false`}},
+		{in: `[null]`, tokens: []string{`[`, `null`, `]`}},
+		{in: `[true,false]`, tokens: []string{`[`, `true`, `,`, `false`, `]`}},
+		{in: `{"a":null}`, tokens: []string{`{`, `"a"`, `:`, `null`, `}`}},
+		{in: "true\nfalse", tokens: []string{`true`, `false`}},
+	}
+	for _, tc := range valid {
+		t.Run(tc.in, func(t *testing.T) {
+			scanner := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			for n, want := range tc.tokens {
+				if got := scanner.Next(); string(got) != want {
+					t.Fatalf("%v: expected: %q, got: %q", n+1, want, got)
+				}
+			}
+			if last := scanner.Next(); len(last) > 0 {
+				t.Fatalf("expected: %q, got: %q", "", last)
+			}
+		})
+	}
+
+	invalid := []string{`trueee`, `nullx`, `falsey`, `true1`, `null_`, `falseE`, `[truex]`, `{"a":nullnull}`}
+	for _, tc := range invalid {
+		t.Run(tc, func(t *testing.T) {
+			scanner := NewScanner(&SmallReader{r: strings.NewReader(tc)})
+			for {
+				tok := scanner.Next()
+				if len(tok) < 1 {
+					break
+				}
+				switch tok[0] {
+				case True, False, Null:
+					t.Fatalf("expected error, got: %q", tok)
+				}
+			}
+		})
+	}
+}
+
+func TestScannerNextInto(t *testing.T) {
+	in := `{"a": [1, "two", true], "b": null}`
+	want := []string{`{`, `"a"`, `:`, `[`, `1`, `,`, `"two"`, `,`, `true`, `]`, `,`, `"b"`, `:`, `null`, `}`}
+	scanner := NewScanner(&SmallReader{r: strings.NewReader(in)})
+	var got [][]byte
+	for {
+		tok, err := scanner.NextInto(nil)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tok)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v tokens, got %v", len(want), len(got))
+	}
+	for n := range want {
+		if string(got[n]) != want[n] {
+			t.Fatalf("%v: expected: %q, got: %q", n+1, want[n], got[n])
+		}
+	}
+
+	// reusing dst does not allocate once it is large enough.
+	buf := make([]byte, 0, 16)
+	scanner = NewScanner(strings.NewReader(in))
+	tok, err := scanner.NextInto(buf)
+	check(t, err)
+	if &tok[0] != &buf[:1][0] {
+		t.Fatalf("expected token to be copied into dst")
+	}
+
+	scanner = NewScanner(strings.NewReader(`[tru`))
+	if _, err := scanner.NextInto(buf); err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := scanner.NextInto(buf); tok != nil || err == nil || err == io.EOF {
+		t.Fatalf("expected error, got: %q, %v", tok, err)
+	}
+}
+
+func TestScannerStrictUTF8(t *testing.T) {
+	tests := []struct {
+		json   string
+		offset int
+	}{
+		{json: "\xff", offset: 0},
+		{json: "  \xff[]", offset: 2},
+		{json: "[\xff]", offset: 1},
+		{json: "[1,\xff2]", offset: 3},
+		{json: "{\"a\"\xff: 1}", offset: 4},
+		{json: "{\"a\": 1}\xff", offset: 8},
+		{json: "123\xff", offset: 3},
+		{json: "[\xe2\x82]", offset: 1},     // truncated multi-byte sequence
+		{json: "[\xc0\xaf]", offset: 1},     // overlong encoding
+		{json: "[\xed\xa0\x80]", offset: 1}, // surrogate half
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%q", tc.json), func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			sc.SetStrictUTF8(true)
+			err := sc.Discard()
+			if !errors.Is(err, ErrInvalidUTF8) {
+				t.Fatalf("expected: %v, got: %v", ErrInvalidUTF8, err)
+			}
+			if want := fmt.Sprintf("invalid UTF-8 at offset %d", tc.offset); err.Error() != want {
+				t.Fatalf("expected: %q, got: %q", want, err)
+			}
+
+			// without strict mode the byte is an invalid token.
+			err = NewScanner(strings.NewReader(tc.json)).Discard()
+			if err == nil || errors.Is(err, ErrInvalidUTF8) {
+				t.Fatalf("expected: invalid token, got: %v", err)
+			}
+		})
+	}
+
+	// valid UTF-8 outside a string is still an invalid token.
+	for _, json := range []string{"[é]", "[\xef\xbf\xbd]"} {
+		sc := NewScanner(strings.NewReader(json))
+		sc.SetStrictUTF8(true)
+		if err := sc.Discard(); err == nil || errors.Is(err, ErrInvalidUTF8) {
+			t.Fatalf("%q: expected: invalid token, got: %v", json, err)
+		}
+	}
+}
+
+func TestScannerReset(t *testing.T) {
+	tokens := func(sc *Scanner) []string {
+		var toks []string
+		for {
+			tok := sc.Next()
+			if len(tok) < 1 {
+				return toks
+			}
+			toks = append(toks, string(tok))
+		}
+	}
+
+	sc := NewScanner(strings.NewReader(`{"a": [1, 2, "` + strings.Repeat("x", 5000) + `"`))
+	sc.SetValidate(true)
+	tokens(sc)
+	if err := sc.Error(); err == nil || err == io.EOF {
+		t.Fatalf("expected: error, got: %v", err)
+	}
+	buf := sc.br.data[:1]
+
+	// after Reset, the scanner behaves as a fresh one with the same options.
+	const in = `{"b": null} [true]`
+	sc.Reset(&SmallReader{r: strings.NewReader(in)})
+	got := tokens(sc)
+	want := []string{`{`, `"b"`, `:`, `null`, `}`, `[`, `true`, `]`}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected: %q, got: %q", want, got)
+	}
+	if err := sc.Error(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+	if &sc.br.data[:1][0] != &buf[0] {
+		t.Fatalf("expected the buffer to be reused")
+	}
+
+	sc.Reset(strings.NewReader(`]`))
+	if tok := sc.Next(); len(tok) > 0 {
+		t.Fatalf("expected: validation to be retained, got: %q", tok)
+	}
+	sc.Reset(strings.NewReader(`"abc"`))
+	if tok := sc.Next(); sc.br.pos() != 0 || string(tok) != `"abc"` {
+		t.Fatalf("expected: %q at offset 0, got: %q at offset %d", `"abc"`, tok, sc.br.pos())
+	}
+}
+
+func TestNewScannerBytes(t *testing.T) {
+	data := []byte(` {"a": [1, -2.5e3, "x\"y", true, false, null]} 7 `)
+	want := NewScanner(strings.NewReader(string(data)))
+	sc := NewScannerBytes(data)
+	for {
+		tok := sc.Next()
+		wtok := want.Next()
+		if string(tok) != string(wtok) {
+			t.Fatalf("expected: %q, got: %q", wtok, tok)
+		}
+		if len(tok) < 1 {
+			break
+		}
+		// tokens are slices of data.
+		if off := sc.br.pos(); &tok[0] != &data[off] {
+			t.Fatalf("%q: expected a slice of the input at offset %d", tok, off)
+		}
+	}
+	if err := sc.Error(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+
+	for in, want := range map[string]error{``: io.EOF, `[1, 2`: io.EOF, `"abc`: io.ErrUnexpectedEOF, `tru`: io.ErrUnexpectedEOF, `1.`: io.ErrUnexpectedEOF} {
+		sc := NewScannerBytes([]byte(in))
+		for len(sc.Next()) > 0 {
+		}
+		if err := sc.Error(); err != want {
+			t.Fatalf("%q: expected: %v, got: %v", in, want, err)
+		}
+	}
+
+	// Reset must not write to the caller's data.
+	sc.Reset(strings.NewReader(`"overwritten"`))
+	for len(sc.Next()) > 0 {
+	}
+	if string(data[:5]) != ` {"a"` {
+		t.Fatalf("expected data to be unmodified, got: %q", data)
+	}
+}
+
+func TestScannerDocumentEnd(t *testing.T) {
+	tests := []struct {
+		in   string
+		docs []string
+	}{
+		{in: ``},
+		{in: `{}{}{}`, docs: []string{`{}`, `{}`, `{}`}},
+		{in: "{\"a\": 1}\n{\"b\": [2, {}]}\n", docs: []string{`{"a":1}`, `{"b":[2,{}]}`}},
+		{in: `1 "two" [3] true null`, docs: []string{`1`, `"two"`, `[3]`, `true`, `null`}},
+		{in: `[[[]]][]`, docs: []string{`[[[]]]`, `[]`}},
+		{in: `{"a": [1,`, docs: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			if sc.DocumentEnd() {
+				t.Fatalf("expected: false before the first token")
+			}
+			var docs []string
+			var doc []byte
+			for {
+				tok := sc.Next()
+				if len(tok) < 1 {
+					break
+				}
+				doc = append(doc, tok...)
+				if sc.DocumentEnd() {
+					docs = append(docs, string(doc))
+					doc = doc[:0]
+				}
+			}
+			if sc.DocumentEnd() {
+				t.Fatalf("expected: false at the end of the stream")
+			}
+			if strings.Join(docs, " ") != strings.Join(tc.docs, " ") {
+				t.Fatalf("expected: %q, got: %q", tc.docs, docs)
+			}
+		})
+	}
+}
+
+func TestScannerBuffered(t *testing.T) {
+	tests := []struct {
+		in, doc, rest string
+	}{
+		{in: `{"a": [1, 2]}`, doc: `{"a":[1,2]}`},
+		{in: `{"a": [1, 2]}` + "\r\nEND\r\n", doc: `{"a":[1,2]}`, rest: "\r\nEND\r\n"},
+		{in: `[]{}`, doc: `[]`, rest: `{}`},
+		{in: `12345 trailer`, doc: `12345`, rest: ` trailer`},
+		{in: `"abc"` + strings.Repeat("x", 10000), doc: `"abc"`, rest: strings.Repeat("x", 10000)},
+	}
+
+	for _, tc := range tests {
+		for name, newScanner := range map[string]func(r io.Reader) *Scanner{
+			"reader":      func(r io.Reader) *Scanner { return NewScanner(r) },
+			"smallreader": func(r io.Reader) *Scanner { return NewScanner(&SmallReader{r: r}) },
+		} {
+			t.Run(name+"/"+tc.in, func(t *testing.T) {
+				r := strings.NewReader(tc.in)
+				sc := newScanner(r)
+				var doc []byte
+				for !sc.DocumentEnd() {
+					tok := sc.Next()
+					if len(tok) < 1 {
+						t.Fatalf("unexpected end of document: %v", sc.Error())
+					}
+					doc = append(doc, tok...)
+				}
+				if string(doc) != tc.doc {
+					t.Fatalf("expected: %q, got: %q", tc.doc, doc)
+				}
+				rest, err := io.ReadAll(io.MultiReader(bytes.NewReader(sc.Buffered()), r))
+				check(t, err)
+				if string(rest) != tc.rest {
+					t.Fatalf("expected: %q, got: %q", tc.rest, rest)
+				}
+			})
+		}
+	}
+
+	sc := NewScannerBytes([]byte(`[1] rest`))
+	for !sc.DocumentEnd() {
+		sc.Next()
+	}
+	if got := string(sc.Buffered()); got != " rest" {
+		t.Fatalf("expected: %q, got: %q", " rest", got)
+	}
+}
+
+func TestScannerDone(t *testing.T) {
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{in: `{"a": 1}`},
+		{in: "{\"a\": 1} \r\n\t "},
+		{in: `"abc"   `},
+		{in: `123`},
+		{in: `{"a": 1} garbage`, err: ErrTrailingData},
+		{in: `[1] [2]`, err: ErrTrailingData},
+		{in: `true   x`, err: ErrTrailingData},
+		{in: `1 // comment`, err: ErrTrailingData},
+		{in: `[1, 2`, err: io.ErrUnexpectedEOF},
+		{in: ` `, err: io.ErrUnexpectedEOF},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			for !sc.DocumentEnd() && len(sc.Next()) > 0 {
+			}
+			err := sc.Done()
+			if tc.err == nil && err != nil || !errors.Is(err, tc.err) {
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+
+	// Next may be called until it returns no token.
+	for _, tc := range []struct {
+		in  string
+		err error
+	}{
+		{in: `{"a":1}`},
+		{in: `"abc" `},
+		{in: `1 [2`, err: io.ErrUnexpectedEOF},
+		{in: `1,`, err: io.ErrUnexpectedEOF},
+		{in: `[1, 2`, err: io.ErrUnexpectedEOF},
+		{in: ``, err: io.ErrUnexpectedEOF},
+	} {
+		sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+		for len(sc.Next()) > 0 {
+		}
+		if err := sc.Done(); tc.err == nil && err != nil || !errors.Is(err, tc.err) {
+			t.Fatalf("%s: expected: %v, got: %v", tc.in, tc.err, err)
+		}
+	}
+	sc := NewScanner(strings.NewReader(`[1] 2`))
+	sc.Next()
+	sc.Next()
+	sc.Next()
+	if err := sc.Done(); err == nil || !strings.HasPrefix(err.Error(), "Done: ") {
+		t.Fatalf("expected: an error prefixed with Done, got: %v", err)
+	}
+
+	// the trailing data is still checked after a rollback.
+	sc = NewScannerBytes([]byte(`[1] 2`))
+	for i := 0; i < 3; i++ {
+		sc.Next()
+	}
+	cp := sc.Checkpoint()
+	scanAll(sc)
+	check(t, sc.Rollback(cp))
+	if err := sc.Done(); !errors.Is(err, ErrTrailingData) {
+		t.Fatalf("expected: %v, got: %v", ErrTrailingData, err)
+	}
+
+	sc = NewScanner(strings.NewReader("[1] /* a */ // b\n "))
+	sc.SetAllowComments(true)
+	for !sc.DocumentEnd() {
+		sc.Next()
+	}
+	check(t, sc.Done())
+
+	sc = NewScanner(strings.NewReader(`{"a": 1} x`))
+	sc.Next()
+	if err := sc.Done(); err == nil {
+		t.Fatalf("expected: an error part way through a value")
+	}
+	r := io.MultiReader(strings.NewReader(`{} `), iotest.ErrReader(io.ErrClosedPipe))
+	sc = NewScanner(r)
+	sc.Next()
+	sc.Next()
+	if err := sc.Done(); err != io.ErrClosedPipe {
+		t.Fatalf("expected: %v, got: %v", io.ErrClosedPipe, err)
+	}
+}
+
+func TestNewScannerSize(t *testing.T) {
+	for _, tc := range inputs {
+		data, err := io.ReadAll(fixture(t, tc.path))
+		check(t, err)
+		for _, size := range []int{-1, 0, 1, 100, 1 << 20} {
+			sc := NewScannerSize(bytes.NewReader(data), size)
+			n := 0
+			for len(sc.Next()) > 0 {
+				n++
+			}
+			if n != tc.alltokens {
+				t.Fatalf("%s, size %d: expected: %v tokens, got: %v", tc.path, size, tc.alltokens, n)
+			}
+			if err := sc.Error(); err != io.EOF {
+				t.Fatalf("expected: %v, got: %v", io.EOF, err)
+			}
+		}
+	}
+}
+
+func TestScannerAllowBOM(t *testing.T) {
+	tests := []struct {
+		in     string
+		tokens []string
+	}{
+		{in: "\xef\xbb\xbf", tokens: nil},
+		{in: "\xef\xbb\xbf{}", tokens: []string{`{`, `}`}},
+		{in: "\xef\xbb\xbf [1]", tokens: []string{`[`, `1`, `]`}},
+		{in: "\xef\xbb\xbf\"a\"", tokens: []string{`"a"`}},
+		{in: "12", tokens: []string{`12`}},
+	}
+
+	for _, tc := range tests {
+		for _, r := range []func(string) io.Reader{
+			func(s string) io.Reader { return strings.NewReader(s) },
+			func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		} {
+			t.Run(tc.in, func(t *testing.T) {
+				sc := NewScanner(r(tc.in))
+				sc.SetAllowBOM(true)
+				for n, want := range tc.tokens {
+					if got := sc.Next(); string(got) != want {
+						t.Fatalf("%v: expected: %v, got: %v", n+1, want, string(got))
+					}
+				}
+				if tok := sc.Next(); len(tok) > 0 {
+					t.Fatalf("expected: no token, got: %q", tok)
+				}
+				if err := sc.Error(); err != io.EOF {
+					t.Fatalf("expected: %v, got: %v", io.EOF, err)
+				}
+			})
+		}
+	}
+
+	// a partial BOM, one other than at the start, or any BOM without
+	// SetAllowBOM, is an error.
+	for _, in := range []string{"\xef\xbb", "\xef\xbb{}", " \xef\xbb\xbf{}", "{}\xef\xbb\xbf{}"} {
+		sc := NewScannerBytes([]byte(in))
+		sc.SetAllowBOM(true)
+		for len(sc.Next()) > 0 {
+		}
+		var serr *SyntaxError
+		if err := sc.Error(); !errors.As(err, &serr) && err != io.ErrUnexpectedEOF {
+			t.Fatalf("%q: expected a syntax error, got: %v", in, err)
+		}
+	}
+	sc := NewScannerBytes([]byte("\xef\xbb\xbf{}"))
+	if tok := sc.Next(); len(tok) > 0 {
+		t.Fatalf("expected: no token, got: %q", tok)
+	}
+}
+
+func TestScannerSetMaxTotalBytes(t *testing.T) {
+	tests := []struct {
+		in     string
+		max    int64
+		tokens []string
+		err    error
+	}{
+		{in: `[1, true]`, max: 9, tokens: []string{`[`, `1`, `,`, `true`, `]`}, err: io.EOF},
+		{in: `[1, true]`, max: 100, tokens: []string{`[`, `1`, `,`, `true`, `]`}, err: io.EOF},
+		{in: `[1, true]`, max: 8, tokens: []string{`[`, `1`, `,`}, err: ErrInputTooLarge},
+		{in: `[1, true]`, max: 7, tokens: []string{`[`, `1`, `,`}, err: ErrInputTooLarge},
+		{in: `[123, true]`, max: 4, tokens: []string{`[`}, err: ErrInputTooLarge},
+		{in: `[123, true]`, max: 3, tokens: []string{`[`}, err: ErrInputTooLarge},
+		{in: `["abc"]`, max: 6, tokens: []string{`[`, `"abc"`}, err: ErrInputTooLarge},
+		{in: `["abc"]`, max: 5, tokens: []string{`[`}, err: ErrInputTooLarge},
+		{in: strings.Repeat(`{"a": 1}`, 1000), max: 4000, tokens: nil, err: ErrInputTooLarge},
+	}
+
+	for _, tc := range tests {
+		for _, r := range []func(string) io.Reader{
+			func(s string) io.Reader { return strings.NewReader(s) },
+			func(s string) io.Reader { return &SmallReader{r: strings.NewReader(s)} },
+		} {
+			t.Run(fmt.Sprintf("%.20s/%d", tc.in, tc.max), func(t *testing.T) {
+				sc := NewScanner(r(tc.in))
+				sc.SetMaxTotalBytes(tc.max)
+				var got []string
+				for tok := sc.Next(); len(tok) > 0; tok = sc.Next() {
+					got = append(got, string(tok))
+				}
+				if tc.tokens != nil && fmt.Sprint(got) != fmt.Sprint(tc.tokens) {
+					t.Fatalf("expected: %q, got: %q", tc.tokens, got)
+				}
+				if err := sc.Error(); !errors.Is(err, tc.err) {
+					t.Fatalf("expected: %v, got: %v", tc.err, err)
+				}
+				if tc.err == ErrInputTooLarge && sc.br.base+int64(len(sc.br.data)) > tc.max {
+					t.Fatalf("expected: at most %d bytes buffered", tc.max)
+				}
+			})
+		}
+	}
+}
+
+func TestScannerSetMaxDepth(t *testing.T) {
+	in := strings.Repeat("[", 1<<20)
+	sc := NewScanner(strings.NewReader(in))
+	sc.SetMaxDepth(1000)
+	n := 0
+	for len(sc.Next()) > 0 {
+		n++
+	}
+	if n != 1000 {
+		t.Fatalf("expected: %d tokens, got: %d", 1000, n)
+	}
+	err := sc.Error()
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("expected: %v, got: %v", ErrMaxDepthExceeded, err)
+	}
+	if want := "maximum nesting depth exceeded: maximum 1000 at offset 1000"; err.Error() != want {
+		t.Fatalf("expected: %q, got: %q", want, err)
+	}
+	if tok := sc.Next(); len(tok) > 0 {
+		t.Fatalf("expected: no token after the error, got: %q", tok)
+	}
+
+	// without a limit, the depth is only bounded by the input.
+	sc = NewScanner(strings.NewReader(in))
+	n = 0
+	for len(sc.Next()) > 0 {
+		n++
+	}
+	if n != 1<<20 || sc.Error() != io.EOF {
+		t.Fatalf("expected: %d tokens, %v, got: %d, %v", 1<<20, io.EOF, n, sc.Error())
+	}
+
+	// closing brackets make room for more.
+	in = strings.Repeat(`{"a": [1, {"b": []}], "c": [[]]}`, 100)
+	sc = NewScanner(&SmallReader{r: strings.NewReader(in)})
+	sc.SetMaxDepth(4)
+	if err := sc.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	sc = NewScanner(strings.NewReader(`[{"a": [[]]}]`))
+	sc.SetMaxDepth(3)
+	if err := sc.Discard(); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("expected: %v, got: %v", ErrMaxDepthExceeded, err)
+	}
+}
+
+func TestScannerSetMaxTokenSize(t *testing.T) {
+	tests := []struct {
+		in       string
+		tokens   int // tokens before the error
+		tooLarge bool
+	}{
+		{in: `["` + strings.Repeat("x", 14) + `"]`, tokens: 3},
+		{in: `["` + strings.Repeat("x", 15) + `"]`, tokens: 1, tooLarge: true},
+		{in: `[` + strings.Repeat("1", 16) + `]`, tokens: 3},
+		{in: `[` + strings.Repeat("1", 17) + `]`, tokens: 1, tooLarge: true},
+		{in: `[-1.` + strings.Repeat("5", 20) + `e10]`, tokens: 1, tooLarge: true},
+		{in: `{"a": "` + strings.Repeat("\\n", 100) + `"}`, tokens: 3, tooLarge: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			sc.SetMaxTokenSize(16)
+			n := 0
+			for len(sc.Next()) > 0 {
+				n++
+			}
+			if n != tc.tokens {
+				t.Fatalf("expected: %d tokens, got: %d", tc.tokens, n)
+			}
+			err := sc.Error()
+			if tc.tooLarge != errors.Is(err, ErrTokenTooLarge) {
+				t.Fatalf("expected: %v, got: %v", ErrTokenTooLarge, err)
+			}
+		})
+	}
+
+	// the buffer does not grow to hold a huge token.
+	sc := NewScanner(strings.NewReader(`"` + strings.Repeat("x", 1<<20) + `"`))
+	sc.SetMaxTokenSize(1 << 10)
+	if tok := sc.Next(); len(tok) > 0 {
+		t.Fatalf("expected: no token, got: %d bytes", len(tok))
+	}
+	if err := sc.Error(); err == nil || err.Error() != "token too large: maximum 1024 bytes at offset 0" {
+		t.Fatalf("expected: %v, got: %v", ErrTokenTooLarge, err)
+	}
+	if n := cap(sc.br.data); n > 8<<10 {
+		t.Fatalf("expected a bounded buffer, got: %d bytes", n)
+	}
+}
+
+// cancelReader reads from r, cancelling a context after the first read.
+type cancelReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (c *cancelReader) Read(buf []byte) (int, error) {
+	defer c.cancel()
+	return c.r.Read(buf[:min(len(buf), 8)])
+}
+
+func TestNewScannerContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &cancelReader{r: strings.NewReader(`[1, 2, "three", 4]`), cancel: cancel}
+	sc := NewScannerContext(ctx, r)
+	var got []string
+	for {
+		tok, err := sc.NextInto(nil)
+		if err != nil {
+			if err != context.Canceled {
+				t.Fatalf("expected: %v, got: %v", context.Canceled, err)
+			}
+			break
+		}
+		got = append(got, string(tok))
+	}
+	// the partial string token is not returned.
+	if want := []string{`[`, `1`, `,`, `2`, `,`}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected: %q, got: %q", want, got)
+	}
+	if err := sc.Error(); err != context.Canceled {
+		t.Fatalf("expected: %v, got: %v", context.Canceled, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	sc = NewScannerContext(ctx, strings.NewReader(`{}`))
+	if tok := sc.Next(); len(tok) > 0 || sc.Error() != context.DeadlineExceeded {
+		t.Fatalf("expected: %v, got: %q, %v", context.DeadlineExceeded, tok, sc.Error())
+	}
+}
+
+func TestScannerAllowComments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		err  bool // the stream does not end cleanly
+	}{
+		{in: `// leading` + "\n" + `[1, /* two */ 2]`, want: []string{`[`, `1`, `,`, `2`, `]`}},
+		{in: `{"a": /**/ true /* a ** b */}`, want: []string{`{`, `"a"`, `:`, `true`, `}`}},
+		{in: `{"a": "//not a comment"}`, want: []string{`{`, `"a"`, `:`, `"//not a comment"`, `}`}},
+		{in: `[1] // runs to EOF`, want: []string{`[`, `1`, `]`}},
+		{in: `[1] /* unterminated`, want: []string{`[`, `1`, `]`}, err: true},
+		{in: `[1] /* unterminated *`, want: []string{`[`, `1`, `]`}, err: true},
+		{in: `[1 / 2]`, want: []string{`[`, `1`}, err: true},
+		{in: `[1 /x 2]`, want: []string{`[`, `1`}, err: true},
+		{in: `[1] /`, want: []string{`[`, `1`, `]`}, err: true},
+		{in: `[/***/1]`, want: []string{`[`, `1`, `]`}},
+		{in: `[1 /*/ 2]`, want: []string{`[`, `1`}, err: true},
+	}
+
+	for _, tc := range tests {
+		for name, r := range map[string]func(string) io.Reader{
+			"small":   func(s string) io.Reader { return &SmallReader{r: strings.NewReader(s)} },
+			"onebyte": func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		} {
+			t.Run(name+"/"+tc.in, func(t *testing.T) {
+				sc := NewScanner(r(tc.in))
+				sc.SetAllowComments(true)
+				var got []string
+				for {
+					tok := sc.Next()
+					if len(tok) < 1 {
+						break
+					}
+					got = append(got, string(tok))
+				}
+				if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+					t.Fatalf("expected: %q, got: %q", tc.want, got)
+				}
+				if err := sc.tokenErr(io.EOF); tc.err != (err != io.EOF) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			})
+		}
+	}
+
+	// a comment counts against the maximum token size.
+	for _, c := range []string{"// %s\n", "/* %s */"} {
+		in := fmt.Sprintf("[1, "+c+"2]", strings.Repeat("/ * ", 1<<20))
+		sc := NewScanner(strings.NewReader(in))
+		sc.SetAllowComments(true)
+		sc.SetMaxTokenSize(1024)
+		got, err := scanAll(sc)
+		if !errors.Is(err, ErrTokenTooLarge) || fmt.Sprint(got) != `[[ 1 ,]` {
+			t.Fatalf("expected: [[ 1 ,], %v, got: %v, %v", ErrTokenTooLarge, got, err)
+		}
+		if n := cap(sc.br.data); n > 64<<10 {
+			t.Fatalf("expected: a bounded buffer, got: %d bytes", n)
+		}
+
+		sc = NewScanner(strings.NewReader(fmt.Sprintf("[1, "+c+"2]", "short")))
+		sc.SetAllowComments(true)
+		sc.SetMaxTokenSize(16)
+		if got, err := scanAll(sc); err != io.EOF || fmt.Sprint(got) != `[[ 1 , 2 ]]` {
+			t.Fatalf("expected: [[ 1 , 2 ]], got: %v, %v", got, err)
+		}
+	}
+
+	// comments are rejected by default.
+	sc := NewScanner(strings.NewReader(`[1 /* two */]`))
+	for i := 0; i < 2; i++ {
+		sc.Next()
+	}
+	if tok := sc.Next(); len(tok) > 0 {
+		t.Fatalf("expected: no token, got: %q", tok)
+	}
+}
+
+func TestScannerAllowNonFiniteNumbers(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: `[NaN, Infinity, -Infinity, -1]`, want: []string{`[`, `NaN`, `,`, `Infinity`, `,`, `-Infinity`, `,`, `-1`, `]`}},
+		{in: `{"a":NaN}`, want: []string{`{`, `"a"`, `:`, `NaN`, `}`}},
+		{in: `-Infinity`, want: []string{`-Infinity`}},
+		{in: `[NaNa]`, want: []string{`[`}},
+		{in: `[Infinit]`, want: []string{`[`}},
+		{in: `[-Inf]`, want: []string{`[`}},
+		{in: `[nan]`, want: []string{`[`}},
+		{in: `[-]`, want: []string{`[`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(iotest.OneByteReader(strings.NewReader(tc.in)))
+			sc.SetAllowNonFiniteNumbers(true)
+			var got []string
+			for {
+				kind, tok := sc.NextToken()
+				if len(tok) < 1 {
+					break
+				}
+				if v, err := ParseFloat(tok); err == nil && (math.IsNaN(v) || math.IsInf(v, 0)) && kind != KindNumber {
+					t.Fatalf("%s: expected: %v, got: %v", tok, KindNumber, kind)
+				}
+				got = append(got, string(tok))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("expected: %q, got: %q", tc.want, got)
+			}
+		})
+	}
+
+	// the literals are rejected by default.
+	for _, in := range []string{`NaN`, `Infinity`, `-Infinity`} {
+		if tok := NewScanner(strings.NewReader(in)).Next(); len(tok) > 0 {
+			t.Fatalf("%s: expected: no token, got: %q", in, tok)
+		}
+	}
+}
+
+func TestScannerSyntaxError(t *testing.T) {
+	tests := []struct {
+		json   string
+		tokens int // tokens before the error
+		offset int64
+		c      byte
+		msg    string
+	}{
+		{json: `[tru]`, tokens: 1, offset: 1, c: 't', msg: `invalid token "tru" at offset 1`},
+		{json: `[1, x]`, tokens: 3, offset: 4, c: 'x', msg: `invalid token "x" at offset 4`},
+		{json: `{"a": -}`, tokens: 3, offset: 6, c: '-', msg: `invalid token "-" at offset 6`},
+		{json: `nul1`, tokens: 0, offset: 0, c: 'n', msg: `invalid token "nul1" at offset 0`},
+		{json: `["a\qb"]`, tokens: 1, offset: 3, c: '\\', msg: `invalid escape sequence in string at offset 3`},
+		{json: "[\"a\x01\"]", tokens: 1, offset: 3, c: 0x01, msg: `control character in string at offset 3`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(strings.NewReader(tc.json))
+			n := 0
+			for len(sc.Next()) > 0 {
+				n++
+			}
+			if n != tc.tokens {
+				t.Fatalf("expected: %d tokens, got: %d", tc.tokens, n)
+			}
+			var serr *SyntaxError
+			if err := sc.Error(); !errors.As(err, &serr) {
+				t.Fatalf("expected: *SyntaxError, got: %T %v", err, err)
+			}
+			if serr.Offset != tc.offset || serr.Byte != tc.c || serr.Error() != tc.msg {
+				t.Fatalf("expected: %d %q %q, got: %d %q %q", tc.offset, tc.c, tc.msg, serr.Offset, serr.Byte, serr.Error())
+			}
+			// the scanner stops at the error.
+			if tok := sc.Next(); len(tok) > 0 {
+				t.Fatalf("expected: no token, got: %q", tok)
+			}
+		})
+	}
+
+	// the end of the stream is not a syntax error, though it may be unexpected.
+	for in, want := range map[string]error{`[1, 2]`: io.EOF, ` `: io.EOF, `[1, tr`: io.ErrUnexpectedEOF, `["abc`: io.ErrUnexpectedEOF} {
+		sc := NewScanner(strings.NewReader(in))
+		for len(sc.Next()) > 0 {
+		}
+		if err := sc.Error(); err != want {
+			t.Fatalf("%q: expected: %v, got: %v", in, want, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package json
+
+import (
+	"fmt"
+	"io"
+)
+
+// Stats summarises the contents of a stream of JSON values, see
+// Scanner.Stats.
+type Stats struct {
+	Documents int   // top-level values
+	Objects   int   // objects, at any depth
+	Arrays    int   // arrays, at any depth
+	Keys      int   // object keys
+	Strings   int   // strings, excluding object keys
+	Numbers   int   // numbers
+	Bools     int   // true and false
+	Nulls     int   // null
+	MaxDepth  int   // deepest nesting of objects and arrays
+	Bytes     int64 // length of the stream in bytes, including whitespace
+}
+
+// Stats consumes the rest of the stream, counting the values of each type it
+// holds and the depth to which they are nested, without otherwise processing
+// them. As with NextValue, Stats only checks that brackets are properly
+// nested and matched, unless SetValidate is enabled. Stats returns nil if the
+// stream ends cleanly, or the statistics gathered so far and the error which
+// stopped it, io.ErrUnexpectedEOF if the stream ends part way through a
+// value.
+func (s *Scanner) Stats() (Stats, error) {
+	var st Stats
+	var nesting stack
+	prev := KindInvalid
+	for {
+		tok := s.Next()
+		if len(tok) < 1 {
+			break
+		}
+		if !nesting.nest(tok) {
+			st.Bytes = s.br.pos()
+			return st, fmt.Errorf("Stats: unexpected %s at offset %d", describe(tok), s.br.pos())
+		}
+		kind := tokenKind(tok)
+		switch kind {
+		case KindObjectStart:
+			st.Objects++
+		case KindArrayStart:
+			st.Arrays++
+		case KindColon:
+			if prev == KindString {
+				// the string was a key.
+				st.Strings--
+				st.Keys++
+			}
+		case KindString:
+			st.Strings++
+		case KindNumber:
+			st.Numbers++
+		case KindTrue, KindFalse:
+			st.Bools++
+		case KindNull:
+			st.Nulls++
+		}
+		st.MaxDepth = max(st.MaxDepth, s.depth)
+		if s.DocumentEnd() {
+			st.Documents++
+		}
+		prev = kind
+	}
+	st.Bytes = s.br.pos()
+	if s.depth > 0 {
+		return st, s.tokenErr(io.ErrUnexpectedEOF)
+	}
+	return st, s.tokenErr(nil)
+}
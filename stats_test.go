@@ -0,0 +1,69 @@
+package json
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScannerStats(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Stats
+		err  error
+	}{
+		{in: ``},
+		{in: `  `, want: Stats{Bytes: 2}},
+		{in: `1`, want: Stats{Documents: 1, Numbers: 1, Bytes: 1}},
+		{
+			in:   `{"a": [1, "two", true, false, null, {}], "b": {"c": [[]]}}`,
+			want: Stats{Documents: 1, Objects: 3, Arrays: 3, Keys: 3, Strings: 1, Numbers: 1, Bools: 2, Nulls: 1, MaxDepth: 4, Bytes: 58},
+		},
+		{in: "{}\n[]\n\"x\"\n", want: Stats{Documents: 3, Objects: 1, Arrays: 1, Strings: 1, MaxDepth: 1, Bytes: 10}},
+		{in: `[1, [2`, want: Stats{Arrays: 2, Numbers: 2, MaxDepth: 2, Bytes: 6}, err: io.ErrUnexpectedEOF},
+	}
+
+	for _, tc := range []struct {
+		in   string
+		want Stats
+	}{
+		{in: `]`},
+		{in: `[}`, want: Stats{Arrays: 1, MaxDepth: 1, Bytes: 1}},
+		{in: `{"a": [1}`, want: Stats{Objects: 1, Arrays: 1, Keys: 1, Numbers: 1, MaxDepth: 2, Bytes: 8}},
+		{in: `[1] ]`, want: Stats{Documents: 1, Arrays: 1, Numbers: 1, MaxDepth: 1, Bytes: 4}},
+		{in: `1, 2`, want: Stats{Documents: 1, Numbers: 1, Bytes: 1}},
+	} {
+		got, err := NewScanner(strings.NewReader(tc.in)).Stats()
+		if err == nil || !strings.HasPrefix(err.Error(), "Stats: unexpected") {
+			t.Fatalf("%s: expected: an unexpected token error, got: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: expected: %+v, got: %+v", tc.in, tc.want, got)
+		}
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			got, err := sc.Stats()
+			if err != tc.err {
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected: %+v, got: %+v", tc.want, got)
+			}
+		})
+	}
+
+	for _, tc := range inputs {
+		r := fixture(t, tc.path)
+		st, err := NewScanner(r).Stats()
+		check(t, err)
+		if n := 2*(st.Objects+st.Arrays) + st.Keys + st.Strings + st.Numbers + st.Bools + st.Nulls; n != tc.tokens {
+			t.Fatalf("%s: expected: %d values, got: %d", tc.path, tc.tokens, n)
+		}
+		if st.Bytes != r.Size() {
+			t.Fatalf("%s: expected: %d bytes, got: %d", tc.path, r.Size(), st.Bytes)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// StringChunks consumes the next token in the stream, which must be a string,
+// without buffering it in full. fn is called with successive chunks of the
+// string's content, excluding the surrounding quotes, as they are read from
+// the underlying reader; last is true for the final chunk, which may be empty.
+// Escape sequences are passed through as is, but are never split across
+// chunks, nor are the two halves of an escaped UTF-16 surrogate pair.
+// The content is checked as Next checks a string; if it is invalid,
+// StringChunks stops, and returns the *SyntaxError which Error reports. An
+// object key, which the validator and CurrentKey need in full, is buffered
+// as it is read.
+//
+// The chunk is only valid for the duration of the call to fn. If fn returns
+// an error, StringChunks stops and returns that error.
+// At the end of the input stream, StringChunks returns io.EOF.
+func (s *Scanner) StringChunks(fn func(chunk []byte, last bool) error) error {
+	s.br.release(s.offset)
+	s.offset = 0
+	w := s.br.window()
+	for {
+		pos := 0
+		for pos < len(w) && whitespace[w[pos]] {
+			pos++
+		}
+		s.br.release(pos)
+		if pos < len(w) {
+			break
+		}
+		if s.br.extend() == 0 {
+			return s.tokenErr(io.EOF)
+		}
+		w = s.br.window()
+	}
+	if c := s.br.window()[0]; c != String {
+		return fmt.Errorf("StringChunks: expected string, got %v at offset %d", kinds[c], s.br.pos())
+	}
+	start := s.br.pos()
+	key := s.keyNext || s.validate && s.valid.expectingKey()
+	if s.validate {
+		if s.err != nil {
+			return s.err
+		}
+		if !key {
+			// the content of a value is not needed to check it.
+			if err := s.valid.step([]byte{String}); err != nil {
+				s.err = fmt.Errorf("%w at offset %d", err, start)
+				return s.err
+			}
+		}
+	}
+	if key {
+		s.keyBuf = append(s.keyBuf[:0], String)
+	}
+	s.br.release(1)
+
+	var st stringScan
+	offset := 0
+	for {
+		w = s.br.window()
+		n, done, err := st.scan(w, offset)
+		switch {
+		case err != nil:
+			s.invalidString(err, n)
+			return s.err
+		case done:
+			if key {
+				if err := s.endKey(append(append(s.keyBuf, w[:n]...), String), start); err != nil {
+					return err
+				}
+			}
+			if err := fn(w[:n], true); err != nil {
+				return err
+			}
+			s.br.release(n + 1)
+			return nil
+		}
+		if i := st.complete(n); i > 0 {
+			if err := fn(w[:i], false); err != nil {
+				return err
+			}
+			if key {
+				s.keyBuf = append(s.keyBuf, w[:i]...)
+			}
+			s.br.release(i)
+			st.shift(i)
+			n -= i
+		}
+		offset = n
+		if s.br.extend() == 0 {
+			if err := s.Error(); err != io.EOF {
+				return err
+			}
+			return io.ErrUnexpectedEOF
+		}
+	}
+}
+
+// endKey records tok, an object key read by StringChunks whose opening quote
+// is at the stream offset start, as Next would have.
+func (s *Scanner) endKey(tok []byte, start int64) error {
+	s.keyBuf = tok
+	if s.validate {
+		if err := s.valid.step(tok); err != nil {
+			s.err = fmt.Errorf("%w at offset %d", err, start)
+			return s.err
+		}
+	}
+	if s.keyNext {
+		s.keyNext = false
+		s.copyKey(tok)
+	}
+	return nil
+}
+
+// DecodeStringAsJSON decodes the token most recently returned by Next, which
+// must be a string whose content is itself a JSON document, as in the value
+// of {"payload": "{\"a\":1}"}, and stores the document in the value pointed
+// to by v as Decoder.Decode would. The string is unescaped before it is
+// parsed. It is an error for the content of the string to be anything but a
+// single valid JSON value.
+func (s *Scanner) DecodeStringAsJSON(v any) error {
+	tok := s.br.window()[:s.offset]
+	if len(tok) < 1 || tok[0] != String {
+		return fmt.Errorf("DecodeStringAsJSON: expected string, got %s at offset %d", describe(tok), s.br.pos())
+	}
+	content, err := Unescape(tok)
+	if err != nil {
+		return fmt.Errorf("DecodeStringAsJSON: %w", err)
+	}
+	b := []byte(content)
+	docs, err := NewScannerBytes(b).documents(b, nil)
+	switch {
+	case err != nil:
+		return fmt.Errorf("DecodeStringAsJSON: string at offset %d does not hold valid JSON: %w", s.br.pos(), err)
+	case len(docs) != 1:
+		return fmt.Errorf("DecodeStringAsJSON: string at offset %d holds %d JSON values, expected 1", s.br.pos(), len(docs))
+	}
+	if err := NewDecoder(bytes.NewReader(docs[0])).Decode(v); err != nil {
+		return fmt.Errorf("DecodeStringAsJSON: %w", err)
+	}
+	return nil
+}
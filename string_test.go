@@ -0,0 +1,222 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScannerStringChunks(t *testing.T) {
+	tests := []string{
+		``,
+		`a`,
+		`\"`,
+		`\\`,
+		`\\\"\\`,
+		`é😀\n`,
+		`\ud83d\ude00`,
+		`\u00e9\ud83d\udE00`,
+		strings.Repeat(`abc\"\\\/\b\f\n\r\tካ😀\u00e9\uD83D\uDE00`, 1000),
+	}
+
+	readers := map[string]func(io.Reader) io.Reader{
+		"small":   func(r io.Reader) io.Reader { return &SmallReader{r: r} },
+		"onebyte": iotest.OneByteReader,
+		"full":    func(r io.Reader) io.Reader { return r },
+	}
+
+	for name, reader := range readers {
+		for _, want := range tests {
+			t.Run(name+"/"+want[:min(len(want), 20)], func(t *testing.T) {
+				in := ` "` + want + `" 1`
+				sc := NewScanner(reader(strings.NewReader(in)))
+				var got []byte
+				last := false
+				err := sc.StringChunks(func(chunk []byte, l bool) error {
+					if last {
+						t.Fatalf("chunk %q after last chunk", chunk)
+					}
+					last = l
+					// each chunk, closed by a quote, is the whole content of
+					// a valid string: no escape, surrogate pair or rune
+					// straddles the end of it.
+					var st stringScan
+					end := append(chunk[:len(chunk):len(chunk)], '"')
+					if n, done, err := st.scan(end, 0); err != nil || !done || n != len(chunk) {
+						t.Fatalf("chunk %q ends with a partial escape: %d %v %v", chunk, n, done, err)
+					}
+					got = append(got, chunk...)
+					return nil
+				})
+				check(t, err)
+				if !last {
+					t.Fatalf("last chunk was not flagged")
+				}
+				if string(got) != want {
+					t.Fatalf("expected: %q, got: %q", want, got)
+				}
+				if tok := sc.Next(); string(tok) != "1" {
+					t.Fatalf("expected: %q, got: %q", "1", tok)
+				}
+			})
+		}
+	}
+}
+
+func TestScannerStringChunksBounded(t *testing.T) {
+	in := `"` + strings.Repeat("x", 1<<20) + `"`
+	sc := NewScanner(strings.NewReader(in))
+	n := 0
+	err := sc.StringChunks(func(chunk []byte, last bool) error {
+		n += len(chunk)
+		return nil
+	})
+	check(t, err)
+	if n != 1<<20 {
+		t.Fatalf("expected: %v, got: %v", 1<<20, n)
+	}
+	if cap(sc.br.data) > newBufferSize {
+		t.Fatalf("expected buffer of at most %v bytes, got %v", newBufferSize, cap(sc.br.data))
+	}
+}
+
+func TestScannerStringChunksInvalid(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		in  string
+		fn  func([]byte, bool) error
+		err error
+	}{
+		{in: ``, err: io.EOF},
+		{in: `  `, err: io.EOF},
+		{in: `"abc`, err: io.ErrUnexpectedEOF},
+		{in: `"abc\`, err: io.ErrUnexpectedEOF},
+		{in: `1`},
+		{in: `{"a": 1}`},
+		{in: `"abc"`, fn: func([]byte, bool) error { return errStop }, err: errStop},
+		{in: `"a\x"`, err: ErrInvalidEscape},
+		{in: `"\ud83d\n"`, err: ErrInvalidEscape},
+		{in: `"\u00e9\ud83d"`, err: ErrInvalidEscape},
+		{in: `"\u12g4"`, err: ErrInvalidEscape},
+		{in: "\"a\x01\"", err: ErrControlCharacter},
+		{in: "\"" + strings.Repeat("a", 5000) + "\xff\"", err: ErrInvalidUTF8},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			fn := tc.fn
+			if fn == nil {
+				fn = func([]byte, bool) error { return nil }
+			}
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			err := sc.StringChunks(fn)
+			var se *SyntaxError
+			switch {
+			case err == nil:
+				t.Fatalf("expected err, got: %v", err)
+			case tc.err != nil && !errors.Is(err, tc.err):
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			case errors.As(err, &se) && sc.Error() != err:
+				t.Fatalf("expected: Error to report %v, got: %v", err, sc.Error())
+			}
+		})
+	}
+}
+
+func TestScannerStringChunksKeys(t *testing.T) {
+	// c reads a string with StringChunks, n any token with Next.
+	sc := NewScanner(&SmallReader{r: strings.NewReader(`{"a": {"b": ["x", "y"], "c": 1}}`)})
+	sc.SetValidate(true)
+	sc.SetTrackPath(true)
+	fn := func([]byte, bool) error { return nil }
+	for _, op := range "ncnncnncnnnncnnnn" {
+		if op == 'c' {
+			check(t, sc.StringChunks(fn))
+		} else if len(sc.Next()) < 1 {
+			t.Fatalf("unexpected end of stream: %v", sc.Error())
+		}
+	}
+	if err := sc.validEnd(); err != nil {
+		t.Fatalf("expected: a valid document, got: %v", err)
+	}
+
+	sc = NewScanner(strings.NewReader(`{"a\"b": 1}`))
+	sc.SetTrackPath(true)
+	sc.SetValidate(true)
+	sc.Next()
+	check(t, sc.StringChunks(fn))
+	if got, want := string(sc.CurrentKey()), `a\"b`; got != want {
+		t.Fatalf("expected: key %q, got: %q", want, got)
+	}
+	if got, want := sc.Path(), `/a"b`; got != want {
+		t.Fatalf("expected: path %q, got: %q", want, got)
+	}
+
+	sc = NewScanner(strings.NewReader(`{"a": 1, "\u0061": 2}`))
+	sc.SetValidate(true)
+	sc.SetDisallowDuplicateKeys(true)
+	for i := 0; i < 5; i++ {
+		sc.Next()
+	}
+	if err := sc.StringChunks(fn); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected: %v, got: %v", ErrDuplicateKey, err)
+	}
+}
+
+func TestScannerDecodeStringAsJSON(t *testing.T) {
+	sc := NewScanner(strings.NewReader(`{"payload": "{\"a\": [1, \"xy\"], \"b\": null}", "n": " 42 "}`))
+	for _, want := range []string{`{`, `"payload"`, `:`} {
+		if tok := string(sc.Next()); tok != want {
+			t.Fatalf("expected: %q, got: %q", want, tok)
+		}
+	}
+	sc.Next()
+	var payload map[string]interface{}
+	if err := sc.DecodeStringAsJSON(&payload); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"a": []interface{}{1.0, "xy"}, "b": nil}
+	if !reflect.DeepEqual(want, payload) {
+		t.Fatalf("expected: %v, got: %v", want, payload)
+	}
+
+	for _, want := range []string{`,`, `"n"`, `:`, `" 42 "`} {
+		if tok := string(sc.Next()); tok != want {
+			t.Fatalf("expected: %q, got: %q", want, tok)
+		}
+	}
+	var n int
+	if err := sc.DecodeStringAsJSON(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 42 {
+		t.Fatalf("expected: %v, got: %v", 42, n)
+	}
+}
+
+func TestScannerDecodeStringAsJSONInvalid(t *testing.T) {
+	tests := []struct {
+		json, want string
+	}{
+		{json: `123`, want: `DecodeStringAsJSON: expected string, got number 123 at offset 0`},
+		{json: ``, want: `DecodeStringAsJSON: expected string, got end of stream at offset 0`},
+		{json: `""`, want: `DecodeStringAsJSON: string at offset 0 holds 0 JSON values, expected 1`},
+		{json: `"1 2"`, want: `DecodeStringAsJSON: string at offset 0 holds 2 JSON values, expected 1`},
+		{json: `"{\"a\":"`, want: `DecodeStringAsJSON: string at offset 0 does not hold valid JSON: expected value: unexpected EOF`},
+		{json: `"not json"`, want: `DecodeStringAsJSON: string at offset 0 does not hold valid JSON: invalid token "not" at offset 0`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(strings.NewReader(tc.json))
+			sc.Next()
+			var v interface{}
+			if err := sc.DecodeStringAsJSON(&v); err == nil || err.Error() != tc.want {
+				t.Fatalf("expected: %q, got: %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// A TokenType classifies a lexical token returned by Scanner.NextToken.
+type TokenType uint8
+
+const (
+	KindInvalid     TokenType = iota // not a valid token
+	KindObjectStart                  // {
+	KindObjectEnd                    // }
+	KindArrayStart                   // [
+	KindArrayEnd                     // ]
+	KindColon                        // :
+	KindComma                        // ,
+	KindString                       // "
+	KindNumber                       // -, 0-9
+	KindTrue                         // true
+	KindFalse                        // false
+	KindNull                         // null
+	KindEOF                          // end of stream, see Scanner.SetEOFToken
+)
+
+var tokenNames = [...]string{
+	KindInvalid:     "invalid token",
+	KindObjectStart: "object start",
+	KindObjectEnd:   "object end",
+	KindArrayStart:  "array start",
+	KindArrayEnd:    "array end",
+	KindColon:       "colon",
+	KindComma:       "comma",
+	KindString:      "string",
+	KindNumber:      "number",
+	KindTrue:        "true",
+	KindFalse:       "false",
+	KindNull:        "null",
+	KindEOF:         "end of stream",
+}
+
+// String returns a short description of the token type, e.g. "object start".
+func (t TokenType) String() string {
+	if int(t) < len(tokenNames) {
+		return tokenNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", t)
+}
+
+// maxDescribeLen is the number of bytes of a token DescribeToken includes
+// before truncating it.
+const maxDescribeLen = 32
+
+// DescribeToken returns a short human readable description of the token raw,
+// of type tokenType, for use in error messages.
+// Strings, numbers, and invalid tokens longer than a few dozen bytes are
+// truncated, so the description stays short regardless of the size of raw.
+func DescribeToken(tokenType TokenType, raw []byte) string {
+	switch tokenType {
+	case KindObjectStart, KindObjectEnd, KindArrayStart, KindArrayEnd, KindColon, KindComma:
+		return fmt.Sprintf("%v '%c'", tokenType, "?{}[]:,"[tokenType])
+	case KindTrue, KindFalse, KindNull, KindEOF:
+		return tokenType.String()
+	case KindString:
+		if len(raw) < 2 {
+			// the content is unknown, as for a string read by StringChunks.
+			return tokenType.String()
+		}
+		if len(raw) > maxDescribeLen {
+			return fmt.Sprintf("%v %s...\" (truncated)", tokenType, truncate(raw[:len(raw)-1]))
+		}
+		return fmt.Sprintf("%v %s", tokenType, raw)
+	case KindNumber:
+		if len(raw) > maxDescribeLen {
+			return fmt.Sprintf("%v %s... (truncated)", tokenType, truncate(raw))
+		}
+		return fmt.Sprintf("%v %s", tokenType, raw)
+	default:
+		if len(raw) > maxDescribeLen {
+			return fmt.Sprintf("%v %q... (truncated)", KindInvalid, truncate(raw))
+		}
+		return fmt.Sprintf("%v %q", KindInvalid, raw)
+	}
+}
+
+// describe returns the DescribeToken description of tok.
+func describe(tok []byte) string {
+	if len(tok) < 1 {
+		return KindEOF.String()
+	}
+	return DescribeToken(tokenKind(tok), tok)
+}
+
+// truncate shortens b to at most maxDescribeLen bytes without splitting a
+// UTF-8 encoded rune.
+func truncate(b []byte) []byte {
+	if len(b) <= maxDescribeLen {
+		return b
+	}
+	n := maxDescribeLen
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return b[:n]
+}
+
+// kinds maps the first byte of a token to its TokenType.
+var kinds = [256]TokenType{
+	ObjectStart: KindObjectStart,
+	ObjectEnd:   KindObjectEnd,
+	ArrayStart:  KindArrayStart,
+	ArrayEnd:    KindArrayEnd,
+	Colon:       KindColon,
+	Comma:       KindComma,
+	String:      KindString,
+	True:        KindTrue,
+	False:       KindFalse,
+	Null:        KindNull,
+	'-':         KindNumber,
+	'0':         KindNumber,
+	'1':         KindNumber,
+	'2':         KindNumber,
+	'3':         KindNumber,
+	'4':         KindNumber,
+	'5':         KindNumber,
+	'6':         KindNumber,
+	'7':         KindNumber,
+	'8':         KindNumber,
+	'9':         KindNumber,
+}
+
+// tokenKind returns the TokenType of tok, as kinds does, except that NaN and
+// Infinity, which Next returns only if SetAllowNonFiniteNumbers is enabled,
+// are numbers.
+func tokenKind(tok []byte) TokenType {
+	switch string(tok) {
+	case "NaN", "Infinity":
+		return KindNumber
+	default:
+		return kinds[tok[0]]
+	}
+}
+
+// SetEOFToken controls whether NextToken reports the end of the stream as a
+// token of its own. When enabled, once the underlying reader is cleanly
+// exhausted, NextToken returns KindEOF exactly once before returning
+// KindInvalid. No KindEOF token is returned if the stream ends with an error.
+func (s *Scanner) SetEOFToken(v bool) {
+	s.eofToken = v
+}
+
+// NextToken returns the type of the next lexical token in the stream along
+// with the token itself. The []byte is identical to the one Next would have
+// returned and is valid until Next or NextToken is called again.
+// If the stream is at its end, or an error has occurred, NextToken returns
+// KindInvalid and a zero length []byte slice, unless SetEOFToken is enabled.
+func (s *Scanner) NextToken() (TokenType, []byte) {
+	tok := s.Next()
+	if len(tok) > 0 {
+		return tokenKind(tok), tok
+	}
+	// a clean end of stream leaves nothing unconsumed in the window.
+	if s.eofToken && !s.eofSent && s.Error() == io.EOF && len(s.br.window()) == 0 {
+		s.eofSent = true
+		return KindEOF, nil
+	}
+	return KindInvalid, nil
+}
+
+// PeekType reports the type of the JSON value at the start of r, skipping any
+// leading whitespace, without reading or validating the rest of the value.
+// Both true and false are reported as their own TokenType.
+//
+// If r implements io.ByteScanner, as bufio.Reader, bytes.Reader and
+// strings.Reader do, the first byte of the value is unread, so r is left
+// positioned at the start of the value. Otherwise r is read one byte at a
+// time and the first byte of the value is consumed.
+// If r holds only whitespace, PeekType returns io.EOF.
+func PeekType(r io.Reader) (TokenType, error) {
+	var br io.ByteReader = &oneByteReader{r: r}
+	bs, ok := r.(io.ByteScanner)
+	if ok {
+		br = bs
+	}
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return KindInvalid, err
+		}
+		if whitespace[c] {
+			continue
+		}
+		if ok {
+			if err := bs.UnreadByte(); err != nil {
+				return KindInvalid, err
+			}
+		}
+		switch kind := kinds[c]; kind {
+		case KindObjectStart, KindArrayStart, KindString, KindNumber, KindTrue, KindFalse, KindNull:
+			return kind, nil
+		default:
+			return KindInvalid, fmt.Errorf("PeekType: unexpected %s at start of value", DescribeToken(kind, []byte{c}))
+		}
+	}
+}
+
+// oneByteReader adapts an io.Reader to an io.ByteReader by reading a byte at
+// a time, so that PeekType reads no further than the first byte of the value.
+type oneByteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (o *oneByteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(o.r, o.buf[:])
+	return o.buf[0], err
+}
@@ -0,0 +1,189 @@
+package json
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScannerNextToken(t *testing.T) {
+	in := `{"a": [1, -2.5, "three", true, false, null]}`
+	want := []TokenType{
+		KindObjectStart, KindString, KindColon, KindArrayStart,
+		KindNumber, KindComma, KindNumber, KindComma, KindString, KindComma,
+		KindTrue, KindComma, KindFalse, KindComma, KindNull,
+		KindArrayEnd, KindObjectEnd,
+	}
+	scanner := NewScanner(&SmallReader{r: strings.NewReader(in)})
+	for n, kind := range want {
+		got, tok := scanner.NextToken()
+		if got != kind {
+			t.Fatalf("%v: expected: %v, got: %v, %q", n+1, kind, got, tok)
+		}
+	}
+	if got, tok := scanner.NextToken(); got != KindInvalid || len(tok) > 0 {
+		t.Fatalf("expected: %v, got: %v, %q", KindInvalid, got, tok)
+	}
+}
+
+func TestScannerNextTokenMatchesNext(t *testing.T) {
+	in := `{"a": [1, -2.5e3, "th\"ree", true, false, null], "b": {}}`
+	next := NewScanner(strings.NewReader(in))
+	scanner := NewScanner(strings.NewReader(in))
+	for {
+		want := next.Next()
+		_, got := scanner.NextToken()
+		if string(got) != string(want) {
+			t.Fatalf("expected: %q, got: %q", want, got)
+		}
+		if len(want) < 1 {
+			break
+		}
+	}
+}
+
+func TestScannerEOFToken(t *testing.T) {
+	tests := []struct {
+		in    string
+		r     func(string) io.Reader
+		kinds []TokenType
+	}{
+		{in: ``, kinds: []TokenType{KindEOF}},
+		{in: ` `, kinds: []TokenType{KindEOF}},
+		{in: `1`, kinds: []TokenType{KindNumber, KindEOF}},
+		{in: `[true] `, kinds: []TokenType{KindArrayStart, KindTrue, KindArrayEnd, KindEOF}},
+		{in: `[tru`, kinds: []TokenType{KindArrayStart}},
+		{in: `"abc`, kinds: nil},
+		{in: `[1`, kinds: []TokenType{KindArrayStart, KindNumber}, r: func(in string) io.Reader {
+			return io.MultiReader(strings.NewReader(in), iotest.ErrReader(io.ErrClosedPipe))
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			var r io.Reader = strings.NewReader(tc.in)
+			if tc.r != nil {
+				r = tc.r(tc.in)
+			}
+			scanner := NewScanner(&SmallReader{r: r})
+			scanner.SetEOFToken(true)
+			for n, kind := range tc.kinds {
+				got, tok := scanner.NextToken()
+				if got != kind {
+					t.Fatalf("%v: expected: %v, got: %v, %q", n+1, kind, got, tok)
+				}
+			}
+			for i := 0; i < 2; i++ {
+				if got, tok := scanner.NextToken(); got != KindInvalid || len(tok) > 0 {
+					t.Fatalf("expected: %v, got: %v, %q", KindInvalid, got, tok)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeToken(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	tests := []struct {
+		kind TokenType
+		raw  string
+		want string
+	}{
+		{kind: KindObjectStart, raw: `{`, want: `object start '{'`},
+		{kind: KindObjectEnd, raw: `}`, want: `object end '}'`},
+		{kind: KindArrayStart, raw: `[`, want: `array start '['`},
+		{kind: KindArrayEnd, raw: `]`, want: `array end ']'`},
+		{kind: KindColon, raw: `:`, want: `colon ':'`},
+		{kind: KindComma, raw: `,`, want: `comma ','`},
+		{kind: KindTrue, raw: `true`, want: `true`},
+		{kind: KindFalse, raw: `false`, want: `false`},
+		{kind: KindNull, raw: `null`, want: `null`},
+		{kind: KindEOF, want: `end of stream`},
+		{kind: KindString, raw: `"foo"`, want: `string "foo"`},
+		{kind: KindString, raw: `"` + long + `"`, want: `string "` + long[:31] + `..." (truncated)`},
+		{kind: KindString, raw: `"` + long[:30] + `€"`, want: `string "` + long[:30] + `..." (truncated)`},
+		{kind: KindNumber, raw: `123`, want: `number 123`},
+		{kind: KindNumber, raw: `1` + long[:40], want: `number 1` + long[:31] + `... (truncated)`},
+		{kind: KindInvalid, raw: "\x00x", want: `invalid token "\x00x"`},
+		{kind: KindInvalid, raw: long, want: `invalid token "` + long[:32] + `"... (truncated)`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			got := DescribeToken(tc.kind, []byte(tc.raw))
+			if got != tc.want {
+				t.Fatalf("expected: %s, got: %s", tc.want, got)
+			}
+		})
+	}
+	// NaN and Infinity are only numbers as whole tokens.
+	for raw, want := range map[string]string{
+		`NaN`:      `number NaN`,
+		`Infinity`: `number Infinity`,
+		`Inf`:      `invalid token "Inf"`,
+		`Nope`:     `invalid token "Nope"`,
+	} {
+		if got := describe([]byte(raw)); got != want {
+			t.Fatalf("%s: expected: %s, got: %s", raw, want, got)
+		}
+	}
+}
+
+func TestPeekType(t *testing.T) {
+	tests := []struct {
+		json string
+		want TokenType
+	}{
+		{json: `{"a": 1}`, want: KindObjectStart},
+		{json: " \n\t[1, 2]", want: KindArrayStart},
+		{json: `"abc`, want: KindString},
+		{json: `-1`, want: KindNumber},
+		{json: `0`, want: KindNumber},
+		{json: `true`, want: KindTrue},
+		{json: `  false`, want: KindFalse},
+		{json: `null`, want: KindNull},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			r := strings.NewReader(tc.json)
+			got, err := PeekType(r)
+			check(t, err)
+			if got != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+			// the value itself is left unread.
+			rest, err := io.ReadAll(r)
+			check(t, err)
+			if want := strings.TrimLeft(tc.json, " \n\t"); string(rest) != want {
+				t.Fatalf("expected: %q, got: %q", want, rest)
+			}
+
+			// a reader which cannot unread loses only the first byte of the
+			// value.
+			r = strings.NewReader(tc.json)
+			got, err = PeekType(iotest.OneByteReader(r))
+			check(t, err)
+			if got != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+			rest, err = io.ReadAll(r)
+			check(t, err)
+			if want := strings.TrimLeft(tc.json, " \n\t")[1:]; string(rest) != want {
+				t.Fatalf("expected: %q, got: %q", want, rest)
+			}
+		})
+	}
+
+	for _, in := range []string{``, `   `} {
+		if _, err := PeekType(strings.NewReader(in)); err != io.EOF {
+			t.Fatalf("%q: expected: %v, got: %v", in, io.EOF, err)
+		}
+	}
+	for _, in := range []string{`]`, `}`, `,`, `:`, `x`, "\xef", `NaN`, `Inf`} {
+		if _, err := PeekType(strings.NewReader(in)); err == nil || err == io.EOF {
+			t.Fatalf("%q: expected err, got: %v", in, err)
+		}
+	}
+}
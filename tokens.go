@@ -0,0 +1,92 @@
+//go:build go1.23
+
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Tokens returns an iterator over the remaining lexical tokens in the stream,
+// as returned by Next, so that the stream can be scanned with
+//
+//	for tok := range s.Tokens() {
+//		...
+//	}
+//
+// Each token is only valid until the next iteration. The iterator stops at
+// the end of the stream, or once an error occurs; as with Next, Error reports
+// which.
+func (s *Scanner) Tokens() iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for {
+			tok := s.Next()
+			if len(tok) < 1 || !yield(tok) {
+				return
+			}
+		}
+	}
+}
+
+// TypedTokens is like Tokens, but also yields the type of each token, as
+// NextToken does. If SetEOFToken is enabled, the final token is KindEOF.
+func (s *Scanner) TypedTokens() iter.Seq2[TokenType, []byte] {
+	return func(yield func(TokenType, []byte) bool) {
+		for {
+			kind, tok := s.NextToken()
+			if kind == KindInvalid || !yield(kind, tok) {
+				return
+			}
+		}
+	}
+}
+
+// errStopped is returned to elements when the loop ranging over an iterator
+// stops early.
+var errStopped = errors.New("iteration stopped")
+
+// ArrayElements consumes the opening bracket of the next value in the stream,
+// which must be an array, and returns an iterator over the bytes of each of
+// its elements in turn, as Array does, so that an array of any size can be
+// processed one element at a time:
+//
+//	elems, err := s.ArrayElements()
+//	if err != nil {
+//		...
+//	}
+//	for elem := range elems {
+//		...
+//	}
+//	if err := s.Error(); err != nil && err != io.EOF {
+//		...
+//	}
+//
+// Each element is only valid until the next iteration, and the iterator may
+// only be ranged over once. Once the closing bracket has been consumed, the
+// scanner is positioned after it and the iterator stops. If the elements are
+// malformed, or the stream ends part way through the array, the iterator
+// stops early and Error reports why. If the value is not an array,
+// ArrayElements returns an error; at the end of the input stream, it returns
+// io.EOF.
+func (s *Scanner) ArrayElements() (iter.Seq[[]byte], error) {
+	tok := s.Next()
+	if len(tok) < 1 {
+		return nil, s.tokenErr(io.EOF)
+	}
+	if tok[0] != ArrayStart {
+		return nil, fmt.Errorf("ArrayElements: expected array, got %s at offset %d", describe(tok), s.br.pos())
+	}
+	return func(yield func([]byte) bool) {
+		err := s.elements("ArrayElements", func(elem []byte) error {
+			if !yield(elem) {
+				return errStopped
+			}
+			return nil
+		})
+		if err != nil && err != errStopped && s.err == nil {
+			s.err = err
+		}
+	}, nil
+}
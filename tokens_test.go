@@ -0,0 +1,128 @@
+//go:build go1.23
+
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScannerTokens(t *testing.T) {
+	sc := NewScanner(&SmallReader{r: strings.NewReader(`{"a": [1, true]}`)})
+	var got []string
+	for tok := range sc.Tokens() {
+		got = append(got, string(tok))
+	}
+	if want := `[{ "a" : [ 1 , true ] }]`; fmt.Sprint(got) != want {
+		t.Fatalf("expected: %s, got: %s", want, got)
+	}
+	if err := sc.Error(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+
+	// the iterator stops at an error.
+	sc = NewScanner(strings.NewReader(`[1, "two`))
+	n := 0
+	for range sc.Tokens() {
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("expected: 3 tokens, got: %d", n)
+	}
+	if err := sc.tokenErr(io.EOF); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+
+	// breaking out of the loop leaves the rest of the stream.
+	sc = NewScanner(strings.NewReader(`1 2 3`))
+	for tok := range sc.Tokens() {
+		if string(tok) == "2" {
+			break
+		}
+	}
+	if tok := sc.Next(); string(tok) != "3" {
+		t.Fatalf("expected: 3, got: %q", tok)
+	}
+}
+
+func TestScannerTypedTokens(t *testing.T) {
+	sc := NewScanner(strings.NewReader(`["a", null]`))
+	sc.SetEOFToken(true)
+	var got []TokenType
+	for kind, tok := range sc.TypedTokens() {
+		if kind != KindEOF && kinds[tok[0]] != kind {
+			t.Fatalf("%q: expected: %v, got: %v", tok, kinds[tok[0]], kind)
+		}
+		got = append(got, kind)
+	}
+	want := []TokenType{KindArrayStart, KindString, KindComma, KindNull, KindArrayEnd, KindEOF}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected: %v, got: %v", want, got)
+	}
+}
+
+func TestScannerArrayElements(t *testing.T) {
+	tests := []struct {
+		in    string
+		elems []string
+		err   error
+	}{
+		{in: `[]`, elems: nil},
+		{in: `[1, "two", {"a": [3]}, [], null]`, elems: []string{`1`, `"two"`, `{"a": [3]}`, `[]`, `null`}},
+		{in: `[1, 2`, elems: []string{`1`, `2`}, err: io.ErrUnexpectedEOF},
+		{in: `[1 2]`, elems: []string{`1`}, err: errors.New("expected comma or array end, got number 2 at offset 3")},
+		{in: `[1, }]`, elems: []string{`1`}, err: errors.New("ArrayElements: unexpected object end '}' at offset 4")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			elems, err := sc.ArrayElements()
+			check(t, err)
+			var got []string
+			for elem := range elems {
+				got = append(got, string(elem))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.elems) {
+				t.Fatalf("expected: %q, got: %q", tc.elems, got)
+			}
+			err = sc.Error()
+			switch {
+			case tc.err == nil && err != nil && err != io.EOF:
+				t.Fatalf("expected: no error, got: %v", err)
+			case tc.err != nil && (err == nil || err.Error() != tc.err.Error()):
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+
+	// the scanner is left after the array, or where the loop stopped.
+	sc := NewScanner(strings.NewReader(`[1, 2, 3] true`))
+	elems, err := sc.ArrayElements()
+	check(t, err)
+	for elem := range elems {
+		if string(elem) == "2" {
+			break
+		}
+	}
+	if tok := sc.Next(); string(tok) != "," {
+		t.Fatalf("expected: %q, got: %q", ",", tok)
+	}
+	sc = NewScanner(strings.NewReader(`[1] true`))
+	elems, err = sc.ArrayElements()
+	check(t, err)
+	for range elems {
+	}
+	if tok := sc.Next(); string(tok) != "true" {
+		t.Fatalf("expected: %q, got: %q", "true", tok)
+	}
+
+	for _, in := range []string{`{}`, `1`, ``} {
+		if _, err := NewScanner(strings.NewReader(in)).ArrayElements(); err == nil {
+			t.Fatalf("%q: expected an error", in)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// NormalizeNumbers copies the JSON values read from r to w, rewriting each
+// number into a canonical form. Strings, literals, and structural tokens are
+// copied as is; insignificant whitespace is removed. Successive top-level
+// values are separated by newlines.
+//
+// Integers are left unchanged. Numbers with a fraction or an exponent are
+// written with a decimal point and at least one fractional digit, trailing
+// zeros in the fraction removed, and a lower case exponent without a plus
+// sign or leading zeros, which is omitted if zero. For example, 1.50E+03
+// becomes 1.5e3 and 2e0 becomes 2.0. The rewrite is textual, so the value of
+// a number is never altered, however many digits it has.
+func NormalizeNumbers(w io.Writer, r io.Reader) error {
+	var buf []byte
+	return copyTokens("NormalizeNumbers", w, r, func(bw *bufio.Writer, tok []byte, depth int) {
+		if kinds[tok[0]] == KindNumber {
+			buf = appendCanonicalNumber(buf[:0], tok)
+			tok = buf
+		}
+		bw.Write(tok)
+	})
+}
+
+// appendCanonicalNumber appends the canonical form of the number token tok to
+// dst, as described by NormalizeNumbers.
+func appendCanonicalNumber(dst, tok []byte) []byte {
+	mantissa, exp := tok, []byte(nil)
+	if i := bytes.IndexAny(tok, "eE"); i >= 0 {
+		mantissa, exp = tok[:i], tok[i+1:]
+	}
+	integer, frac := mantissa, []byte(nil)
+	if i := bytes.IndexByte(mantissa, '.'); i >= 0 {
+		integer, frac = mantissa[:i], mantissa[i+1:]
+	}
+	if frac == nil && exp == nil {
+		return append(dst, tok...)
+	}
+
+	dst = append(dst, integer...)
+	dst = append(dst, '.')
+	if frac = bytes.TrimRight(frac, "0"); len(frac) > 0 {
+		dst = append(dst, frac...)
+	} else {
+		dst = append(dst, '0')
+	}
+
+	if len(exp) > 0 {
+		neg := exp[0] == '-'
+		if exp[0] == '-' || exp[0] == '+' {
+			exp = exp[1:]
+		}
+		if exp = bytes.TrimLeft(exp, "0"); len(exp) > 0 {
+			dst = append(dst, 'e')
+			if neg {
+				dst = append(dst, '-')
+			}
+			dst = append(dst, exp...)
+		}
+	}
+	return dst
+}
+
+// Compact copies the JSON values read from src to dst with all insignificant
+// whitespace removed, as encoding/json's Compact does. Successive top-level
+// values are separated by newlines. Tokens are copied as is, so strings keep
+// their escape sequences.
+func Compact(dst io.Writer, src io.Reader) error {
+	return copyTokens("Compact", dst, src, func(bw *bufio.Writer, tok []byte, depth int) {
+		bw.Write(tok)
+	})
+}
+
+// Indent copies the JSON values read from src to dst, reformatted as
+// encoding/json's Indent does: each member of an object, and each element of
+// an array, begins on a new line, starting with prefix followed by one copy
+// of indent for each level of nesting, and colons are followed by a space.
+// Empty objects and arrays are written as {} and []. Successive top-level
+// values are separated by newlines; unlike encoding/json, whitespace
+// following the last value is not copied. Tokens are copied as is, so
+// strings keep their escape sequences.
+func Indent(dst io.Writer, src io.Reader, prefix, indent string) error {
+	open := false // the last token opened an object or array
+	newline := func(bw *bufio.Writer, depth int) {
+		bw.WriteByte('\n')
+		bw.WriteString(prefix)
+		for i := 0; i < depth; i++ {
+			bw.WriteString(indent)
+		}
+	}
+	return copyTokens("Indent", dst, src, func(bw *bufio.Writer, tok []byte, depth int) {
+		switch tok[0] {
+		case ObjectEnd, ArrayEnd:
+			if !open {
+				newline(bw, depth-1)
+			}
+			open = false
+			bw.Write(tok)
+			return
+		case Colon:
+			bw.WriteString(": ")
+			return
+		}
+		if open {
+			newline(bw, depth)
+		}
+		open = tok[0] == ObjectStart || tok[0] == ArrayStart
+		bw.Write(tok)
+		if tok[0] == Comma {
+			newline(bw, depth)
+		}
+	})
+}
+
+// copyTokens copies the JSON values read from src to dst, checking their
+// structure, calling write to write each token. depth is the number of
+// objects and arrays open before tok. Successive top-level values are
+// separated by newlines. name is used to prefix errors.
+func copyTokens(name string, dst io.Writer, src io.Reader, write func(bw *bufio.Writer, tok []byte, depth int)) error {
+	s := NewScanner(src)
+	bw := bufio.NewWriter(dst)
+	started := false
+	for {
+		tok := s.Next()
+		if len(tok) < 1 {
+			break
+		}
+		depth := s.valid.len()
+		if err := s.valid.step(tok); err != nil {
+			return fmt.Errorf("%s: %w at offset %d", name, err, s.br.pos())
+		}
+		if depth == 0 {
+			if started {
+				bw.WriteByte('\n')
+			}
+			started = true
+		}
+		write(bw, tok, depth)
+	}
+	switch err := s.tokenErr(nil); {
+	case err == io.ErrUnexpectedEOF, err == nil && !s.valid.complete():
+		return fmt.Errorf("%s: %w", name, s.valid.truncated())
+	case err != nil:
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,141 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeNumbers(t *testing.T) {
+	tests := []struct {
+		json, want string
+	}{
+		{json: `1`, want: `1`},
+		{json: `-0`, want: `-0`},
+		{json: `123456789012345678901234567890`, want: `123456789012345678901234567890`},
+		{json: `1.0`, want: `1.0`},
+		{json: `2.000`, want: `2.0`},
+		{json: `1.50`, want: `1.5`},
+		{json: `0.000100`, want: `0.0001`},
+		{json: `1.50E+03`, want: `1.5e3`},
+		{json: `1e5`, want: `1.0e5`},
+		{json: `1E-07`, want: `1.0e-7`},
+		{json: `2e0`, want: `2.0`},
+		{json: `2.5e-00`, want: `2.5`},
+		{json: `-3.14159265358979323846264338327950288E+000010`, want: `-3.14159265358979323846264338327950288e10`},
+		{json: ` { "a" : [ 1.10 , "1.10" , true , null ] , "b\"" : { } } `, want: `{"a":[1.1,"1.10",true,null],"b\"":{}}`},
+		{json: "[1.0]\n[2E2]", want: "[1.0]\n[2.0e2]"},
+		{json: `1 2.50`, want: "1\n2.5"},
+		{json: `"a" "b" {}`, want: "\"a\"\n\"b\"\n{}"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NormalizeNumbers(&buf, &SmallReader{r: strings.NewReader(tc.json)})
+			check(t, err)
+			if got := buf.String(); got != tc.want {
+				t.Fatalf("expected: %s, got: %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeNumbersInvalid(t *testing.T) {
+	tests := []struct {
+		json string
+		err  error
+	}{
+		{json: `[1.0`, err: io.ErrUnexpectedEOF},
+		{json: `[1.0,,]`},
+		{json: `{"a" 1}`},
+		{json: `[1.]`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			err := NormalizeNumbers(io.Discard, strings.NewReader(tc.json))
+			switch {
+			case err == nil:
+				t.Fatalf("expected err, got: %v", err)
+			case tc.err != nil && !errors.Is(err, tc.err):
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestIndentCompact(t *testing.T) {
+	tests := []string{
+		`1`,
+		`"aé\n"`,
+		`{}`,
+		`[]`,
+		` { "a" : [ 1 , 2 , { } , [ ] , [ [ ] ] ] , "b" : { "c" : null , "d" : "e\"f" } } `,
+		`[{"a": 1}, {"b": [true, false]}]`,
+		`[[[[1]]]]`,
+	}
+
+	for _, in := range tests {
+		t.Run(in, func(t *testing.T) {
+			testIndentCompact(t, []byte(in))
+		})
+	}
+
+	for _, tc := range inputs {
+		t.Run(tc.path, func(t *testing.T) {
+			data, err := io.ReadAll(fixture(t, tc.path))
+			check(t, err)
+			testIndentCompact(t, data)
+		})
+	}
+}
+
+func testIndentCompact(t *testing.T, in []byte) {
+	t.Helper()
+	var want, got bytes.Buffer
+	// unlike encoding/json, trailing whitespace is not preserved.
+	check(t, json.Indent(&want, bytes.TrimSpace(in), ">", "\t"))
+	check(t, Indent(&got, &SmallReader{r: bytes.NewReader(in)}, ">", "\t"))
+	if !bytes.Equal(want.Bytes(), got.Bytes()) {
+		t.Fatalf("Indent: expected: %s, got: %s", want.Bytes(), got.Bytes())
+	}
+
+	want.Reset()
+	got.Reset()
+	check(t, json.Compact(&want, in))
+	check(t, Compact(&got, &SmallReader{r: bytes.NewReader(in)}))
+	if !bytes.Equal(want.Bytes(), got.Bytes()) {
+		t.Fatalf("Compact: expected: %s, got: %s", want.Bytes(), got.Bytes())
+	}
+}
+
+func TestIndentCompactStream(t *testing.T) {
+	in := `{"a":1} [2]  "three"`
+	var buf bytes.Buffer
+	check(t, Compact(&buf, strings.NewReader(in)))
+	if want := "{\"a\":1}\n[2]\n\"three\""; buf.String() != want {
+		t.Fatalf("Compact: expected: %q, got: %q", want, buf.String())
+	}
+
+	buf.Reset()
+	check(t, Indent(&buf, strings.NewReader(in), "", "  "))
+	if want := "{\n  \"a\": 1\n}\n[\n  2\n]\n\"three\""; buf.String() != want {
+		t.Fatalf("Indent: expected: %q, got: %q", want, buf.String())
+	}
+
+	for _, in := range []string{`[1`, `{"a" 1}`, `[1,]`, `[}`} {
+		if err := Indent(io.Discard, strings.NewReader(in), "", "  "); err == nil {
+			t.Fatalf("Indent %q: expected err, got: %v", in, err)
+		}
+		if err := Compact(io.Discard, strings.NewReader(in)); err == nil {
+			t.Fatalf("Compact %q: expected err, got: %v", in, err)
+		}
+	}
+	if err := Compact(io.Discard, strings.NewReader(`[1`)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+}
@@ -0,0 +1,43 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// UnmarshalObject reads a single JSON object from r and calls fn for each of
+// its members in turn, with the member's key and its value decoded into a T.
+// Members are decoded one at a time as they are read, so the object as a whole
+// is never held in memory.
+// If fn returns an error, UnmarshalObject stops and returns that error.
+func UnmarshalObject[T any](r io.Reader, fn func(key string, value T) error) error {
+	d := NewDecoder(r)
+	tok, err := d.NextToken()
+	if err != nil {
+		return err
+	}
+	if tok[0] != ObjectStart {
+		return fmt.Errorf("UnmarshalObject: expected object, got %s", describe(tok))
+	}
+	for {
+		tok, err := d.NextToken()
+		if err != nil {
+			return err
+		}
+		if tok[0] == ObjectEnd {
+			return nil
+		}
+		key, err := d.scanner.KeyString(tok)
+		if err != nil {
+			return err
+		}
+		var value T
+		if err := d.decodeValue(reflect.ValueOf(&value).Elem()); err != nil {
+			return fmt.Errorf("UnmarshalObject: key %q: %w", key, err)
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+}
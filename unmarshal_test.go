@@ -0,0 +1,93 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalObject(t *testing.T) {
+	in := `{"a": 1, "b": 2, "c": -3}`
+	var keys []string
+	var values []int
+	err := UnmarshalObject(&SmallReader{r: strings.NewReader(in)}, func(key string, value int) error {
+		keys = append(keys, key)
+		values = append(values, value)
+		return nil
+	})
+	check(t, err)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(want, keys) {
+		t.Fatalf("expected: %v, got: %v", want, keys)
+	}
+	if want := []int{1, 2, -3}; !reflect.DeepEqual(want, values) {
+		t.Fatalf("expected: %v, got: %v", want, values)
+	}
+
+	in = `{"x": {"a": 1}, "y": {}, "z": {"b": "c", "d": [true]}}`
+	got := make(map[string]map[string]interface{})
+	err = UnmarshalObject(strings.NewReader(in), func(key string, value map[string]interface{}) error {
+		got[key] = value
+		return nil
+	})
+	check(t, err)
+	want := map[string]map[string]interface{}{
+		"x": {"a": 1.0},
+		"y": {},
+		"z": {"b": "c", "d": []interface{}{true}},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("expected: %v, got: %v", want, got)
+	}
+
+	in = `{"a\"b": 1, "caf\u00e9": 2}`
+	keys = nil
+	err = UnmarshalObject(strings.NewReader(in), func(key string, value int) error {
+		keys = append(keys, key)
+		return nil
+	})
+	check(t, err)
+	if want := []string{`a"b`, "café"}; !reflect.DeepEqual(want, keys) {
+		t.Fatalf("expected: %q, got: %q", want, keys)
+	}
+
+	err = UnmarshalObject(strings.NewReader(`{}`), func(key string, value string) error {
+		t.Fatalf("unexpected member %q", key)
+		return nil
+	})
+	check(t, err)
+}
+
+func TestUnmarshalObjectInvalid(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		in  string
+		fn  func(string, string) error
+		err error
+	}{
+		{in: ``, err: io.ErrUnexpectedEOF},
+		{in: `[]`},
+		{in: `"a"`},
+		{in: `{"a": 1}`},
+		{in: `{"a": "b"`, err: io.ErrUnexpectedEOF},
+		{in: `{"a" "b"}`},
+		{in: `{"a": "b"}`, fn: func(string, string) error { return errStop }, err: errStop},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			fn := tc.fn
+			if fn == nil {
+				fn = func(string, string) error { return nil }
+			}
+			err := UnmarshalObject(strings.NewReader(tc.in), fn)
+			switch {
+			case err == nil:
+				t.Fatalf("expected err, got: %v", err)
+			case tc.err != nil && !errors.Is(err, tc.err):
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,486 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A validator checks that a stream of lexical tokens, as returned by
+// Scanner.Next, forms a sequence of well formed JSON values.
+type validator struct {
+	state   uint8  // what the next token must be
+	allowed uint16 // bitmask of permitted top-level TokenTypes, 0 for all
+	stack          // open containers, true for objects
+
+	keyFn func(key []byte) error // see Scanner.SetKeyValidator
+	path  []pathElem             // position in each open container, tracked when keyFn or trackPath is set
+
+	trailingCommas bool                  // permit a comma before a closing bracket
+	dupKeys        bool                  // reject duplicate keys, see Scanner.SetDisallowDuplicateKeys
+	keys           []map[string]struct{} // keys seen in each open object, tracked when dupKeys is set
+	trackPath      bool                  // see Scanner.SetTrackPath
+}
+
+// A pathElem records the current key or index within an open container.
+type pathElem struct {
+	key   string
+	index int
+}
+
+// validator states.
+const (
+	expectValue           = iota // a value, or a further top-level value
+	expectValueOrArrayEnd        // following [
+	expectKeyOrObjectEnd         // following {
+	expectKey                    // following , in an object
+	expectColon                  // following a key
+	expectCommaOrEnd             // following a value in an object or array
+)
+
+// step advances the validator over tok, returning an error if tok is not
+// permitted at this point in the stream.
+func (v *validator) step(tok []byte) error {
+	switch v.state {
+	case expectValueOrArrayEnd:
+		if tok[0] == ArrayEnd {
+			v.close()
+			return nil
+		}
+		return v.value(tok)
+	case expectKeyOrObjectEnd:
+		if tok[0] == ObjectEnd {
+			v.close()
+			return nil
+		}
+		return v.key(tok)
+	case expectKey:
+		if tok[0] == ObjectEnd && v.trailingCommas {
+			v.close()
+			return nil
+		}
+		return v.key(tok)
+	case expectColon:
+		if tok[0] != Colon {
+			return fmt.Errorf("expected colon, got %s", describe(tok))
+		}
+		v.state = expectValue
+		return nil
+	case expectCommaOrEnd:
+		inObj := v.stack[v.len()-1]
+		switch {
+		case tok[0] == Comma && inObj:
+			v.state = expectKey
+		case tok[0] == Comma:
+			v.state = expectValue
+			if v.tracking() {
+				v.path[len(v.path)-1].index++
+			}
+		case tok[0] == ObjectEnd && inObj, tok[0] == ArrayEnd && !inObj:
+			v.close()
+		case tok[0] == Colon:
+			return ErrUnexpectedColon
+		default:
+			return fmt.Errorf("expected %s, got %s", v.expected(), describe(tok))
+		}
+		return nil
+	default:
+		// in an array, a value is only expected after a comma.
+		if tok[0] == ArrayEnd && v.trailingCommas && v.len() > 0 && !v.stack[v.len()-1] {
+			v.close()
+			return nil
+		}
+		return v.value(tok)
+	}
+}
+
+func (v *validator) value(tok []byte) error {
+	if v.allowed != 0 && v.len() == 0 {
+		if kind := tokenKind(tok); v.allowed&(1<<kind) == 0 {
+			return fmt.Errorf("%w: %s", ErrDisallowedTopLevel, DescribeToken(kind, tok))
+		}
+	}
+	switch tok[0] {
+	case ObjectStart:
+		v.open(true)
+		v.state = expectKeyOrObjectEnd
+	case ArrayStart:
+		v.open(false)
+		v.state = expectValueOrArrayEnd
+	case Colon:
+		return ErrUnexpectedColon
+	case ObjectEnd, ArrayEnd, Comma:
+		return fmt.Errorf("expected value, got %s", describe(tok))
+	default:
+		v.endValue()
+	}
+	return nil
+}
+
+func (v *validator) key(tok []byte) error {
+	switch tok[0] {
+	case String:
+		v.state = expectColon
+		if v.dupKeys {
+			if err := v.uniqueKey(tok); err != nil {
+				return err
+			}
+		}
+		if !v.tracking() {
+			return nil
+		}
+		key := tok[1 : len(tok)-1]
+		v.path[len(v.path)-1].key = string(key)
+		if v.keyFn == nil {
+			return nil
+		}
+		if err := v.keyFn(key); err != nil {
+			return fmt.Errorf("key at %q: %w", v.pointer(), err)
+		}
+		return nil
+	case Colon:
+		return ErrUnexpectedColon
+	default:
+		return fmt.Errorf("expected %s, got %s", v.expected(), describe(tok))
+	}
+}
+
+// open enters an object, if obj is true, or an array.
+func (v *validator) open(obj bool) {
+	v.push(obj)
+	if v.tracking() {
+		v.path = append(v.path, pathElem{})
+	}
+	if v.dupKeys && obj {
+		for len(v.keys) < v.len() {
+			v.keys = append(v.keys, nil)
+		}
+		if seen := v.keys[v.len()-1]; seen != nil {
+			clear(seen)
+		} else {
+			v.keys[v.len()-1] = make(map[string]struct{})
+		}
+	}
+}
+
+// uniqueKey records the key tok in the innermost open object, returning an
+// error wrapping ErrDuplicateKey if it has been seen before. Keys are compared
+// once unescaped, so "a" and "\u0061" are the same key.
+func (v *validator) uniqueKey(tok []byte) error {
+	key := string(tok[1 : len(tok)-1])
+	if bytes.IndexByte(tok, '\\') >= 0 {
+		var err error
+		if key, err = Unescape(tok); err != nil {
+			return err
+		}
+	}
+	seen := v.keys[v.len()-1]
+	if _, ok := seen[key]; ok {
+		return fmt.Errorf("%w %s", ErrDuplicateKey, describe(tok))
+	}
+	seen[key] = struct{}{}
+	return nil
+}
+
+// close leaves the innermost open container, which is complete.
+func (v *validator) close() {
+	v.pop()
+	if v.tracking() {
+		v.path = v.path[:len(v.path)-1]
+	}
+	v.endValue()
+}
+
+// tracking reports whether the path of the current position is tracked.
+func (v *validator) tracking() bool {
+	return v.keyFn != nil || v.trackPath
+}
+
+// pointerEscaper escapes a key for use as a JSON Pointer reference token.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pointer returns the RFC 6901 JSON Pointer of the most recent value, key or
+// bracket, e.g. /a/2/b.
+func (v *validator) pointer() string {
+	path := v.path
+	if v.state == expectValueOrArrayEnd || v.state == expectKeyOrObjectEnd {
+		// the bracket just opened is the value, it has no position within.
+		path = path[:len(path)-1]
+	}
+	var b strings.Builder
+	for i, elem := range path {
+		b.WriteByte('/')
+		if !v.stack[i] {
+			b.WriteString(strconv.Itoa(elem.index))
+			continue
+		}
+		key := elem.key
+		if strings.IndexByte(key, '\\') >= 0 {
+			if s, err := Unescape([]byte(`"` + key + `"`)); err == nil {
+				key = s
+			}
+		}
+		b.WriteString(pointerEscaper.Replace(key))
+	}
+	return b.String()
+}
+
+// endValue moves the validator past a complete value.
+func (v *validator) endValue() {
+	v.state = expectValue
+	if v.len() > 0 {
+		v.state = expectCommaOrEnd
+	}
+}
+
+// complete reports whether the tokens seen so far form zero or more complete
+// values, that is, whether the stream may end here.
+func (v *validator) complete() bool {
+	return v.state == expectValue && v.len() == 0
+}
+
+// expectingKey reports whether an object key is permitted next.
+func (v *validator) expectingKey() bool {
+	return v.state == expectKeyOrObjectEnd || v.state == expectKey
+}
+
+// expected describes the token the validator expects next.
+func (v *validator) expected() string {
+	switch v.state {
+	case expectValueOrArrayEnd:
+		return "value or array end"
+	case expectKeyOrObjectEnd:
+		return "string key or object end"
+	case expectKey:
+		return "string key"
+	case expectColon:
+		return "colon"
+	case expectCommaOrEnd:
+		if v.stack[v.len()-1] {
+			return "comma or object end"
+		}
+		return "comma or array end"
+	default:
+		return "value"
+	}
+}
+
+// truncated returns the error for a stream which ends before the validator
+// is complete.
+func (v *validator) truncated() error {
+	return fmt.Errorf("expected %s: %w", v.expected(), io.ErrUnexpectedEOF)
+}
+
+// SetAllowedTopLevel restricts the types of top-level values the structural
+// validator accepts to those in types, for example KindObjectStart and
+// KindArrayStart to reject bare scalars. Values of any other type are rejected
+// with ErrDisallowedTopLevel. Booleans must be permitted as KindTrue and
+// KindFalse. With no types, all values are accepted, which is the default.
+func (s *Scanner) SetAllowedTopLevel(types ...TokenType) {
+	s.valid.allowed = 0
+	for _, kind := range types {
+		s.valid.allowed |= 1 << kind
+	}
+}
+
+// SetKeyValidator sets a function which the structural validator calls with
+// each object key in the stream, excluding the surrounding quotes and with any
+// escape sequences left as is. If fn returns an error, validation stops and
+// that error is returned, annotated with the JSON Pointer of the key, as
+// Path reports it, e.g. "/a/2/b".
+// A nil fn, the default, accepts all keys.
+// SetKeyValidator must be called before scanning begins.
+func (s *Scanner) SetKeyValidator(fn func(key []byte) error) {
+	s.valid.keyFn = fn
+}
+
+// SetAllowTrailingCommas controls whether the structural validator permits a
+// comma immediately before the closing bracket of an object or array, as in
+// [1, 2, 3,] or {"a": 1,}. A comma with no value either side, as in [,] or
+// [1,,2], remains an error. Trailing commas are rejected by default.
+// SetAllowTrailingCommas only has an effect when the stream is validated, by
+// SetValidate or Discard.
+func (s *Scanner) SetAllowTrailingCommas(v bool) {
+	s.valid.trailingCommas = v
+}
+
+// SetDisallowDuplicateKeys controls whether the structural validator rejects
+// an object in which a key appears more than once, as some parsers keep the
+// first value and others the last, which an attacker may exploit. Keys are
+// compared once unescaped. Keys in different objects, be they nested or
+// siblings, never collide. A duplicate key is reported with an error wrapping
+// ErrDuplicateKey. Duplicate keys are permitted by default.
+// SetDisallowDuplicateKeys only has an effect when the stream is validated,
+// by SetValidate or Discard.
+func (s *Scanner) SetDisallowDuplicateKeys(v bool) {
+	s.valid.dupKeys = v
+}
+
+// SetTrackPath controls whether the scanner tracks its position within the
+// stream, as reported by Path. The position is derived from the structure of
+// the stream, so enabling SetTrackPath also enables SetValidate.
+// SetTrackPath must be called before scanning begins.
+func (s *Scanner) SetTrackPath(v bool) {
+	s.valid.trackPath = v
+	if v {
+		s.validate = true
+	}
+}
+
+// Path returns the RFC 6901 JSON Pointer of the token most recently returned
+// by Next, e.g. /users/3/name for the key "name", or its value, in the fourth
+// object in the array at key "users". The pointer of an opening or closing
+// bracket is that of the object or array itself; a top-level value has the
+// empty pointer. Within keys, ~ is escaped as ~0 and / as ~1. Path requires
+// SetTrackPath to be enabled, otherwise it returns the empty string.
+func (s *Scanner) Path() string {
+	if !s.valid.trackPath {
+		return ""
+	}
+	return s.valid.pointer()
+}
+
+// SetValidate controls whether Next checks that the tokens it returns form a
+// sequence of well formed JSON values, as Discard does: brackets must be
+// properly nested and matched, and keys, colons, commas and values must appear
+// where the grammar permits them. When a token is not permitted, or the stream
+// ends inside a value, Next returns a zero length []byte slice and Error
+// reports the reason. Validation is disabled by default.
+// SetValidate must be called before scanning begins.
+func (s *Scanner) SetValidate(v bool) {
+	s.validate = v
+}
+
+// validateNext passes tok, the token just scanned, to the validator for
+// Next, recording any error.
+func (s *Scanner) validateNext(tok []byte) []byte {
+	if s.err != nil {
+		return nil
+	}
+	if len(tok) < 1 {
+		if err := s.validEnd(); errors.Is(err, io.ErrUnexpectedEOF) {
+			s.err = err
+		}
+		return nil
+	}
+	if err := s.valid.step(tok); err != nil {
+		s.err = fmt.Errorf("%w at offset %d", err, s.br.pos())
+		return nil
+	}
+	return tok
+}
+
+// Discard consumes the remainder of the stream without returning its tokens,
+// checking that it consists of well formed JSON values, and returns the first
+// error encountered. Discard expects the scanner to be positioned at the start
+// of a value, or between values, and leaves it at the end of the stream.
+// Discard returns nil if the remainder of the stream is valid.
+func (s *Scanner) Discard() error {
+	if s.validate {
+		// Next validates the tokens itself.
+		for len(s.Next()) > 0 {
+		}
+		if s.err != nil {
+			return s.err
+		}
+		return s.validEnd()
+	}
+	for {
+		tok := s.Next()
+		if len(tok) < 1 {
+			return s.validEnd()
+		}
+		if err := s.valid.step(tok); err != nil {
+			return fmt.Errorf("%w at offset %d", err, s.br.pos())
+		}
+	}
+}
+
+// validEnd returns the reason Next last returned no token, given that the
+// tokens before it were passed to the validator: nil if the stream ended
+// cleanly after a complete value, otherwise the error.
+func (s *Scanner) validEnd() error {
+	switch err := s.tokenErr(nil); {
+	case err == io.ErrUnexpectedEOF, err == nil && !s.valid.complete():
+		return s.valid.truncated()
+	default:
+		return err
+	}
+}
+
+// Valid reports whether data holds a single well formed JSON value, optionally
+// surrounded by whitespace. Valid is stricter than encoding/json's Valid
+// about strings: an escaped UTF-16 surrogate must be one half of a pair, so
+// "\ud800" alone is not valid.
+func Valid(data []byte) bool {
+	complete, _ := ValidPrefix(data)
+	return complete
+}
+
+// ValidPrefix reports whether b holds a single complete JSON value, optionally
+// surrounded by whitespace, and whether b is at least a valid prefix of one,
+// that is, whether appending more bytes could make it complete.
+// A complete value is also a valid prefix.
+func ValidPrefix(b []byte) (complete bool, validPrefix bool) {
+	s := NewScannerBytes(b)
+	for {
+		tok := s.Next()
+		if len(tok) < 1 {
+			break
+		}
+		if complete {
+			// only whitespace may follow the value.
+			return false, false
+		}
+		if err := s.valid.step(tok); err != nil {
+			return false, false
+		}
+		complete = s.valid.complete()
+	}
+	if len(s.br.window()) == 0 {
+		return complete, true
+	}
+	// the input ends with a token that could not be scanned; if it is
+	// truncated, rather than invalid, check it would be permitted here.
+	// A string stops short of the end of the input only if it is invalid.
+	var se *SyntaxError
+	w := s.br.window()
+	if complete || errors.As(s.err, &se) || !partialToken(w) {
+		return false, false
+	}
+	return false, s.valid.step(completions[kinds[w[0]]]) == nil
+}
+
+// completions holds an example complete token for each scalar TokenType.
+var completions = [...][]byte{
+	KindString: []byte(`""`),
+	KindNumber: []byte(`0`),
+	KindTrue:   []byte(`true`),
+	KindFalse:  []byte(`false`),
+	KindNull:   []byte(`null`),
+}
+
+// partialToken reports whether w, the unscanned remainder of a stream, is a
+// truncated scalar token which more input could complete.
+func partialToken(w []byte) bool {
+	switch kinds[w[0]] {
+	case KindString:
+		// the closing quote is missing.
+		return true
+	case KindTrue:
+		return strings.HasPrefix("true", string(w))
+	case KindFalse:
+		return strings.HasPrefix("false", string(w))
+	case KindNull:
+		return strings.HasPrefix("null", string(w))
+	case KindNumber:
+		// a digit completes any number prefix: -, 1., 1e, 1e+.
+		tok := append(w[:len(w):len(w)], '0')
+		return len(NewScannerBytes(tok).Next()) == len(tok)
+	default:
+		return false
+	}
+}
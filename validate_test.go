@@ -0,0 +1,536 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScannerDiscard(t *testing.T) {
+	valid := []string{
+		``,
+		` `,
+		`1`,
+		`"a" 1 true [] {}`,
+		`{"a": [1, {"b": null}], "c": {}}`,
+		`[[[[[[{"true":true}]]]]]]`,
+	}
+	for _, in := range valid {
+		t.Run(in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(in)})
+			if err := sc.Discard(); err != nil {
+				t.Fatalf("expected: %v, got: %v", nil, err)
+			}
+			if tok := sc.Next(); len(tok) > 0 {
+				t.Fatalf("expected scanner at EOF, got: %q", tok)
+			}
+		})
+	}
+
+	invalid := []struct {
+		json string
+		err  error
+	}{
+		{json: `[`, err: io.ErrUnexpectedEOF},
+		{json: `{"a":`, err: io.ErrUnexpectedEOF},
+		{json: `{"a" 1}`},
+		{json: `[1 2]`},
+		{json: `[1,]`},
+		{json: `[}`},
+		{json: `{]`},
+		{json: `{"a":1,}`},
+		{json: `{1: 1}`},
+		{json: `]`},
+		{json: `,`},
+		{json: `[1:2]`, err: ErrUnexpectedColon},
+		{json: `:`, err: ErrUnexpectedColon},
+		{json: `{"a"::1}`, err: ErrUnexpectedColon},
+		{json: `[tru]`},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			err := sc.Discard()
+			switch {
+			case err == nil:
+				t.Fatalf("expected err, got: %v", err)
+			case tc.err != nil && !errors.Is(err, tc.err):
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestScannerDiscardAfterPrefix(t *testing.T) {
+	sc := NewScanner(strings.NewReader(`{"a": 1} [2, 3] "four"`))
+	_, _, err := sc.NextValue()
+	check(t, err)
+	if err := sc.Discard(); err != nil {
+		t.Fatalf("expected: %v, got: %v", nil, err)
+	}
+}
+
+func TestValidPrefix(t *testing.T) {
+	tests := []struct {
+		json     string
+		complete bool
+		prefix   bool
+	}{
+		{json: ``, prefix: true},
+		{json: `  `, prefix: true},
+		{json: `1`, complete: true, prefix: true},
+		{json: ` {"a": [1, 2]} `, complete: true, prefix: true},
+		{json: `[`, prefix: true},
+		{json: `[1`, prefix: true},
+		{json: `[1,`, prefix: true},
+		{json: `[1, 2`, prefix: true},
+		{json: `{"a`, prefix: true},
+		{json: `{"a"`, prefix: true},
+		{json: `{"a":`, prefix: true},
+		{json: `{"a": tr`, prefix: true},
+		{json: `{"a": "b\`, prefix: true},
+		{json: `"\u12`, prefix: true},
+		{json: `"\ud800`, prefix: true},
+		{json: "\"\xe2\x82", prefix: true},
+		{json: `[-`, prefix: true},
+		{json: `[1.`, prefix: true},
+		{json: `[1e`, prefix: true},
+		{json: `[1e+`, prefix: true},
+		{json: `[null, f`, prefix: true},
+		{json: `n`, prefix: true},
+		{json: `]`},
+		{json: `[}`},
+		{json: `[1 2`},
+		{json: `[1 -`},
+		{json: `{"a" 1`},
+		{json: `{"a" t`},
+		{json: `{1`},
+		{json: `{"a": x`},
+		{json: `{"a": tx`},
+		{json: `[1..`},
+		{json: `[-a`},
+		{json: `truex`},
+		{json: `1 2`},
+		{json: `{} {`},
+		{json: `[] t`},
+		{json: `1:`},
+		{json: `"\x`},
+		{json: `["a\x`},
+		{json: "\"a\x01"},
+		{json: "{\"a\x01"},
+		{json: `"\u12x`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			complete, prefix := ValidPrefix([]byte(tc.json))
+			if complete != tc.complete || prefix != tc.prefix {
+				t.Fatalf("expected: %v, %v, got: %v, %v", tc.complete, tc.prefix, complete, prefix)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		json string
+		want bool
+	}{
+		{json: `1`, want: true},
+		{json: ` {"a": [1, 2, {"b": null}]} ` + "\n\t", want: true},
+		{json: `"caf\u00e9"`, want: true},
+		{json: `[]`, want: true},
+		{json: ``},
+		{json: `  `},
+		{json: `[1, 2`},
+		{json: `{"a": 1} x`},
+		{json: `{"a": 1}}`},
+		{json: `1 2`},
+		{json: `{} {}`},
+		{json: `[1,]`},
+		{json: `{"a" 1}`},
+		{json: `"\x"`},
+		{json: `tru`},
+		{json: `01`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			if got := Valid([]byte(tc.json)); got != tc.want {
+				t.Fatalf("expected: %v, got: %v", tc.want, got)
+			}
+			if want := json.Valid([]byte(tc.json)); want != tc.want {
+				t.Fatalf("encoding/json: expected: %v, got: %v", tc.want, want)
+			}
+		})
+	}
+
+	// unlike encoding/json, a lone surrogate is rejected.
+	for _, in := range []string{`"\ud800"`, `"\udc00"`, `"\ud800\u0041"`} {
+		if Valid([]byte(in)) || !json.Valid([]byte(in)) {
+			t.Fatalf("%s: expected: invalid, but valid for encoding/json", in)
+		}
+	}
+
+	for _, tc := range inputs {
+		t.Run(tc.path, func(t *testing.T) {
+			data, err := io.ReadAll(fixture(t, tc.path))
+			check(t, err)
+			if !Valid(data) {
+				t.Fatalf("expected: %v, got: %v", true, false)
+			}
+			data = bytes.TrimSpace(data)
+			if Valid(data[:len(data)-1]) {
+				t.Fatalf("truncated: expected: %v, got: %v", false, true)
+			}
+		})
+	}
+}
+
+func TestScannerSetAllowedTopLevel(t *testing.T) {
+	tests := []struct {
+		json    string
+		allowed []TokenType
+		err     error
+	}{
+		{json: `{}`, allowed: []TokenType{KindObjectStart, KindArrayStart}},
+		{json: `[1, "a"]`, allowed: []TokenType{KindObjectStart, KindArrayStart}},
+		{json: `{"a": "b"} [1]`, allowed: []TokenType{KindObjectStart, KindArrayStart}},
+		{json: `"foo"`, allowed: []TokenType{KindObjectStart, KindArrayStart}, err: ErrDisallowedTopLevel},
+		{json: `42`, allowed: []TokenType{KindObjectStart, KindArrayStart}, err: ErrDisallowedTopLevel},
+		{json: `{} 42`, allowed: []TokenType{KindObjectStart}, err: ErrDisallowedTopLevel},
+		{json: `[]`, allowed: []TokenType{KindObjectStart}, err: ErrDisallowedTopLevel},
+		{json: `true`, allowed: []TokenType{KindTrue, KindFalse}},
+		{json: `null`, allowed: []TokenType{KindTrue, KindFalse}, err: ErrDisallowedTopLevel},
+		{json: `"foo"`},
+		{json: `42`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(strings.NewReader(tc.json))
+			sc.SetAllowedTopLevel(tc.allowed...)
+			err := sc.Discard()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestScannerDiscardTruncated(t *testing.T) {
+	tests := []struct {
+		json, want string
+	}{
+		{json: `{`, want: `expected string key or object end: unexpected EOF`},
+		{json: `{"key"`, want: `expected colon: unexpected EOF`},
+		{json: `{"key":`, want: `expected value: unexpected EOF`},
+		{json: `{"key":1`, want: `expected comma or object end: unexpected EOF`},
+		{json: `{"key":1,`, want: `expected string key: unexpected EOF`},
+		{json: `{"key":{"a":[]`, want: `expected comma or object end: unexpected EOF`},
+		{json: `{"key":[`, want: `expected value or array end: unexpected EOF`},
+		{json: `{"key":[1`, want: `expected comma or array end: unexpected EOF`},
+		{json: `{"ke`, want: `expected string key or object end: unexpected EOF`},
+		{json: `{"key":tr`, want: `expected value: unexpected EOF`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			err := sc.Discard()
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("expected: %v, got: %v", io.ErrUnexpectedEOF, err)
+			}
+			if err.Error() != tc.want {
+				t.Fatalf("expected: %q, got: %q", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestScannerSetKeyValidator(t *testing.T) {
+	errBadKey := errors.New("bad key")
+	// only [a-zA-Z0-9_] is permitted.
+	validKey := func(key []byte) error {
+		for _, c := range key {
+			if !identifier[c] {
+				return fmt.Errorf("%w %q", errBadKey, key)
+			}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		json, want string
+	}{
+		{json: `{"a": 1, "b_2": [{"c": true}]}`},
+		{json: `[1, "not a key", {"ok": "bad value"}]`},
+		{json: `{"a b": 1}`, want: `key at "/a b": bad key "a b" at offset 1`},
+		{json: `{"a": {"b": 1, "c-d": 2}}`, want: `key at "/a/c-d": bad key "c-d" at offset 15`},
+		{json: `[[], {}, [0, {"x": {"y": 1}, "z.": 2}]]`, want: `key at "/2/1/z.": bad key "z." at offset 29`},
+		{json: `{"a": [1, 2]} {"\n": 1}`, want: `key at "/\n": bad key "\\n" at offset 15`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			sc.SetKeyValidator(validKey)
+			err := sc.Discard()
+			switch {
+			case tc.want == "" && err != nil:
+				t.Fatalf("expected: <nil>, got: %v", err)
+			case tc.want != "" && !errors.Is(err, errBadKey):
+				t.Fatalf("expected: %v, got: %v", errBadKey, err)
+			case tc.want != "" && err.Error() != tc.want:
+				t.Fatalf("expected: %q, got: %q", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestScannerSetValidate(t *testing.T) {
+	deep := strings.Repeat("[", 10000) + strings.Repeat("]", 10000)
+	tests := []struct {
+		json string
+		want string // the error, if any
+	}{
+		{json: `{"a": [1, {"b": null}], "c": true}`},
+		{json: `1 "two" [3]`},
+		{json: deep},
+		{json: `{]`, want: `expected string key or object end, got array end ']' at offset 1`},
+		{json: `[}`, want: `expected value, got object end '}' at offset 1`},
+		{json: `[1}`, want: `expected comma or array end, got object end '}' at offset 2`},
+		{json: `]`, want: `expected value, got array end ']' at offset 0`},
+		{json: `{"a": 1}}`, want: `expected value, got object end '}' at offset 8`},
+		{json: `[1 2]`, want: `expected comma or array end, got number 2 at offset 3`},
+		{json: `[[`, want: `expected value or array end: unexpected EOF`},
+		{json: `{"a": [1`, want: `expected comma or array end: unexpected EOF`},
+		{json: strings.Repeat("[", 10000) + strings.Repeat("]", 9999), want: `expected comma or array end: unexpected EOF`},
+		{json: deep + "]", want: `expected value, got array end ']' at offset 20000`},
+	}
+
+	for _, tc := range tests {
+		name := tc.json
+		if len(name) > 16 {
+			name = name[:16]
+		}
+		t.Run(name, func(t *testing.T) {
+			want := NewScanner(strings.NewReader(tc.json))
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			sc.SetValidate(true)
+			for {
+				tok := sc.Next()
+				if len(tok) < 1 {
+					break
+				}
+				if wtok := want.Next(); string(tok) != string(wtok) {
+					t.Fatalf("expected: %q, got: %q", wtok, tok)
+				}
+			}
+			err := sc.Error()
+			switch {
+			case tc.want == "" && err != io.EOF:
+				t.Fatalf("expected: %v, got: %v", io.EOF, err)
+			case tc.want != "" && (err == nil || err.Error() != tc.want):
+				t.Fatalf("expected: %q, got: %v", tc.want, err)
+			}
+			// the scanner stops at the error.
+			if tok := sc.Next(); len(tok) > 0 {
+				t.Fatalf("expected: no token, got: %q", tok)
+			}
+		})
+	}
+}
+
+func TestScannerSetValidateStringChunks(t *testing.T) {
+	sc := NewScanner(strings.NewReader(`["a" "b"]`))
+	sc.SetValidate(true)
+	sc.Next()
+	fn := func([]byte, bool) error { return nil }
+	if err := sc.StringChunks(fn); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.StringChunks(fn); err == nil || err.Error() != `expected comma or array end, got string at offset 5` {
+		t.Fatalf("expected: structural error, got: %v", err)
+	}
+}
+
+func TestScannerSetAllowTrailingCommas(t *testing.T) {
+	tests := []struct {
+		json string
+		want string // the error, if any
+	}{
+		{json: `[1, 2, 3,]`},
+		{json: `{"a": 1,}`},
+		{json: `{"a": [1, {"b": null,},], "c": true,}`},
+		{json: `[[],]`},
+		{json: `[,]`, want: `expected value, got comma ',' at offset 1`},
+		{json: `{,}`, want: `expected string key or object end, got comma ',' at offset 1`},
+		{json: `[1,,]`, want: `expected value, got comma ',' at offset 3`},
+		{json: `{"a": 1,,}`, want: `expected string key, got comma ',' at offset 8`},
+		{json: `{"a":}`, want: `expected value, got object end '}' at offset 5`},
+		{json: `[1,}`, want: `expected value, got object end '}' at offset 3`},
+		{json: `{"a": 1,]`, want: `expected string key, got array end ']' at offset 8`},
+		{json: `1,`, want: `expected value, got comma ',' at offset 1`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			sc.SetAllowTrailingCommas(true)
+			err := sc.Discard()
+			switch {
+			case tc.want == "" && err != nil:
+				t.Fatalf("expected: %v, got: %v", nil, err)
+			case tc.want != "" && (err == nil || err.Error() != tc.want):
+				t.Fatalf("expected: %q, got: %v", tc.want, err)
+			}
+
+			// the flag has the same effect on SetValidate.
+			sc.Reset(strings.NewReader(tc.json))
+			sc.SetValidate(true)
+			for len(sc.Next()) > 0 {
+			}
+			if err := sc.Error(); tc.want == "" && err != io.EOF || tc.want != "" && (err == nil || err.Error() != tc.want) {
+				t.Fatalf("SetValidate: expected: %q, got: %v", tc.want, err)
+			}
+		})
+	}
+
+	// trailing commas are rejected by default.
+	for _, in := range []string{`[1, 2, 3,]`, `{"a": 1,}`} {
+		if err := NewScanner(strings.NewReader(in)).Discard(); err == nil {
+			t.Fatalf("%s: expected err, got: %v", in, err)
+		}
+	}
+}
+
+func TestScannerSetDisallowDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		json string
+		want string // the error, if any
+	}{
+		{json: `{"a": 1, "b": 2}`},
+		{json: `{"a": {"a": 1}, "b": {"a": 2}}`},
+		{json: `[{"a": 1}, {"a": 2}]`},
+		{json: `{"a": 1} {"a": 2}`},
+		{json: `{"a": [{"a": 1}], "b": 2}`},
+		{json: `{"a": 1, "a": 2}`, want: `duplicate key string "a" at offset 9`},
+		{json: `{"a": 1, "\u0061": 2}`, want: `duplicate key string "\u0061" at offset 9`},
+		{json: `{"a": {"b": 1}, "a": 2}`, want: `duplicate key string "a" at offset 16`},
+		{json: `[{"a": 1}, {"b": 1, "b": 2}]`, want: `duplicate key string "b" at offset 20`},
+		{json: `{"a": {"b": 1, "c": {}, "b": 2}}`, want: `duplicate key string "b" at offset 24`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.json, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.json)})
+			sc.SetDisallowDuplicateKeys(true)
+			err := sc.Discard()
+			switch {
+			case tc.want == "" && err != nil:
+				t.Fatalf("expected: %v, got: %v", nil, err)
+			case tc.want != "" && (err == nil || err.Error() != tc.want):
+				t.Fatalf("expected: %q, got: %v", tc.want, err)
+			case tc.want != "" && !errors.Is(err, ErrDuplicateKey):
+				t.Fatalf("expected: %v, got: %v", ErrDuplicateKey, err)
+			}
+
+			// the key sets are reused after Reset.
+			sc.Reset(strings.NewReader(tc.json))
+			sc.SetValidate(true)
+			for len(sc.Next()) > 0 {
+			}
+			if err := sc.Error(); tc.want == "" && err != io.EOF || tc.want != "" && !errors.Is(err, ErrDuplicateKey) {
+				t.Fatalf("SetValidate: expected: %q, got: %v", tc.want, err)
+			}
+		})
+	}
+
+	// duplicate keys are permitted by default.
+	if err := NewScanner(strings.NewReader(`{"a": 1, "a": 2}`)).Discard(); err != nil {
+		t.Fatalf("expected: %v, got: %v", nil, err)
+	}
+}
+
+func TestScannerSetTrackPath(t *testing.T) {
+	in := `{"users": [{"name": "a"}, {"name": "b", "tags": []}], "a/b": {"m~n": 1, "x": null}} 2`
+	want := []struct {
+		tok, path string
+	}{
+		{`{`, ``},
+		{`"users"`, `/users`},
+		{`:`, `/users`},
+		{`[`, `/users`},
+		{`{`, `/users/0`},
+		{`"name"`, `/users/0/name`},
+		{`:`, `/users/0/name`},
+		{`"a"`, `/users/0/name`},
+		{`}`, `/users/0`},
+		{`,`, `/users/1`},
+		{`{`, `/users/1`},
+		{`"name"`, `/users/1/name`},
+		{`:`, `/users/1/name`},
+		{`"b"`, `/users/1/name`},
+		{`,`, `/users/1/name`},
+		{`"tags"`, `/users/1/tags`},
+		{`:`, `/users/1/tags`},
+		{`[`, `/users/1/tags`},
+		{`]`, `/users/1/tags`},
+		{`}`, `/users/1`},
+		{`]`, `/users`},
+		{`,`, `/users`},
+		{`"a/b"`, `/a~1b`},
+		{`:`, `/a~1b`},
+		{`{`, `/a~1b`},
+		{`"m~n"`, `/a~1b/m~0n`},
+		{`:`, `/a~1b/m~0n`},
+		{`1`, `/a~1b/m~0n`},
+		{`,`, `/a~1b/m~0n`},
+		{`"x"`, `/a~1b/x`},
+		{`:`, `/a~1b/x`},
+		{`null`, `/a~1b/x`},
+		{`}`, `/a~1b`},
+		{`}`, ``},
+		{`2`, ``},
+	}
+
+	sc := NewScanner(&SmallReader{r: strings.NewReader(in)})
+	sc.SetTrackPath(true)
+	for _, w := range want {
+		tok := sc.Next()
+		if string(tok) != w.tok {
+			t.Fatalf("expected: %q, got: %q", w.tok, tok)
+		}
+		if path := sc.Path(); path != w.path {
+			t.Fatalf("%s: expected: %q, got: %q", tok, w.path, path)
+		}
+	}
+	if tok := sc.Next(); len(tok) > 0 {
+		t.Fatalf("expected: no token, got: %q", tok)
+	}
+	if err := sc.Error(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+
+	// keys are unescaped before they are escaped.
+	sc = NewScanner(strings.NewReader(`{"a\u002fb\n": 1}`))
+	sc.SetTrackPath(true)
+	sc.Next()
+	sc.Next()
+	if path := sc.Path(); path != "/a~1b\n" {
+		t.Fatalf("expected: %q, got: %q", "/a~1b\n", path)
+	}
+
+	// the path is not tracked by default.
+	sc = NewScanner(strings.NewReader(`{"a": 1}`))
+	sc.Next()
+	sc.Next()
+	if path := sc.Path(); path != "" {
+		t.Fatalf("expected: %q, got: %q", "", path)
+	}
+}
@@ -0,0 +1,158 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// NextValue consumes the next complete JSON value in the stream, be it a
+// scalar, or an object or array including all of its nested content, and
+// reports its span: the stream offset of its first byte and its length in
+// bytes, including any whitespace inside the value.
+// The bytes of the value are not copied or retained.
+//
+// NextValue checks that brackets are properly nested and matched, it does not
+// otherwise validate the structure of the value.
+// At the end of the input stream, NextValue returns io.EOF.
+func (s *Scanner) NextValue() (offset, length int64, err error) {
+	tok := s.Next()
+	if len(tok) < 1 {
+		return 0, 0, s.tokenErr(io.EOF)
+	}
+	offset = s.br.pos()
+
+	var nesting stack
+	for {
+		if !nesting.nest(tok) {
+			return offset, 0, fmt.Errorf("NextValue: unexpected %s at offset %d", describe(tok), s.br.pos())
+		}
+		if nesting.len() == 0 {
+			return offset, s.br.pos() + int64(len(tok)) - offset, nil
+		}
+		tok = s.Next()
+		if len(tok) < 1 {
+			return offset, 0, s.tokenErr(io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+// SkipValue consumes the next complete JSON value in the stream, be it a
+// scalar, or an object or array including all of its nested content, leaving
+// the scanner positioned at the token which follows it.
+// As with NextValue, brackets are checked for nesting but the structure of
+// the value is not otherwise validated. If the stream ends part way through
+// the value, SkipValue returns io.ErrUnexpectedEOF.
+// At the end of the input stream, SkipValue returns io.EOF.
+func (s *Scanner) SkipValue() error {
+	_, _, err := s.NextValue()
+	return err
+}
+
+// RawValue consumes the next complete JSON value in the stream, be it a
+// scalar, or an object or array including all of its nested content, and
+// returns its bytes as they appear in the input, including any whitespace and
+// escape sequences inside the value. Unlike the tokens returned by Next, the
+// bytes are copied to a newly allocated slice, which remains valid after
+// further scanning.
+// As with SkipValue, brackets are checked for nesting but the structure of
+// the value is not otherwise validated. If the stream ends part way through
+// the value, RawValue returns io.ErrUnexpectedEOF.
+// At the end of the input stream, RawValue returns nil, io.EOF.
+func (s *Scanner) RawValue() ([]byte, error) {
+	raw, err := s.appendValue(nil)
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// appendValue consumes the next complete JSON value in the stream, as
+// NextValue does, and appends its bytes, including any whitespace inside the
+// value, to dst.
+func (s *Scanner) appendValue(dst []byte) ([]byte, error) {
+	tok := s.Next()
+	if len(tok) < 1 {
+		return dst, s.tokenErr(io.EOF)
+	}
+	return s.appendTokens(dst, tok)
+}
+
+// appendTokens appends the bytes of the value starting with tok, the current
+// token, to dst, consuming the rest of the value.
+func (s *Scanner) appendTokens(dst, tok []byte) ([]byte, error) {
+	var nesting stack
+	for {
+		if !nesting.nest(tok) {
+			return dst, fmt.Errorf("unexpected %s at offset %d", describe(tok), s.br.pos())
+		}
+		dst = append(dst, tok...)
+		if nesting.len() == 0 {
+			return dst, nil
+		}
+		dst = s.appendWhitespace(dst)
+		tok = s.Next()
+		if len(tok) < 1 {
+			return dst, s.tokenErr(io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+// appendWhitespace consumes any whitespace following the current token,
+// appending it to dst.
+func (s *Scanner) appendWhitespace(dst []byte) []byte {
+	s.br.release(s.offset)
+	s.offset = 0
+	for {
+		w := s.br.window()
+		pos := 0
+		for pos < len(w) && whitespace[w[pos]] {
+			pos++
+		}
+		dst = append(dst, w[:pos]...)
+		s.br.release(pos)
+		if pos < len(w) || s.br.extend() == 0 {
+			return dst
+		}
+	}
+}
+
+// nest tracks the nesting of brackets as the tokens of a value are read,
+// reporting whether tok is permitted: closing brackets must match the
+// innermost open bracket, and commas and colons may only appear inside one.
+func (n *stack) nest(tok []byte) bool {
+	switch tok[0] {
+	case ObjectStart, ArrayStart:
+		n.push(tok[0] == ObjectStart)
+	case ObjectEnd, ArrayEnd:
+		if n.len() == 0 || (*n)[n.len()-1] != (tok[0] == ObjectEnd) {
+			return false
+		}
+		n.pop()
+	case Colon, Comma:
+		return n.len() > 0
+	}
+	return true
+}
+
+// tokenErr returns the reason Next last returned no token. If the stream
+// ended cleanly, eof is returned; if it ended in the middle of a token,
+// io.ErrUnexpectedEOF; otherwise the error reported by Error.
+func (s *Scanner) tokenErr(eof error) error {
+	if err := s.Error(); err != nil && err != io.EOF {
+		return err
+	}
+	return eof
+}
+
+// validRune reports whether the window starts with a valid UTF-8 encoded
+// rune, reading more input if the window ends part way through one.
+func (s *Scanner) validRune() bool {
+	for !utf8.FullRune(s.br.window()) {
+		if s.br.extend() == 0 {
+			break
+		}
+	}
+	r, size := utf8.DecodeRune(s.br.window())
+	return r != utf8.RuneError || size > 1
+}
@@ -0,0 +1,178 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScannerNextValue(t *testing.T) {
+	values := []string{
+		`{"a": [1, 2], "b": {"c": "}"}}`,
+		`17`,
+		`"x"`,
+		`[ ]`,
+		`true`,
+		`[[[{"a": null}]], [false]]`,
+	}
+	in := "  " + strings.Join(values, "\n") + "  "
+
+	for _, sz := range []int{1, 8, 64, 4096} {
+		buf := make([]byte, sz)
+		sc := &Scanner{
+			br: byteReader{
+				data: buf[:0],
+				r:    &SmallReader{r: strings.NewReader(in)},
+			},
+		}
+		for _, want := range values {
+			offset, length, err := sc.NextValue()
+			if err != nil {
+				t.Fatalf("%d: %q: %v", sz, want, err)
+			}
+			if got := in[offset : offset+length]; got != want {
+				t.Fatalf("%d: expected: %q, got: %q", sz, want, got)
+			}
+		}
+		if _, _, err := sc.NextValue(); err != io.EOF {
+			t.Fatalf("%d: expected: %v, got: %v", sz, io.EOF, err)
+		}
+	}
+}
+
+func TestScannerNextValueFixtures(t *testing.T) {
+	for _, tc := range inputs {
+		r := fixture(t, tc.path)
+		t.Run(tc.path, func(t *testing.T) {
+			data, err := io.ReadAll(r)
+			check(t, err)
+			sc := NewScanner(bytes.NewReader(data))
+			offset, length, err := sc.NextValue()
+			check(t, err)
+			want := bytes.TrimSpace(data)
+			if got := data[offset : offset+length]; !bytes.Equal(got, want) {
+				t.Fatalf("expected span of %d bytes, got %d at offset %d", len(want), length, offset)
+			}
+		})
+	}
+}
+
+func TestScannerNextValueInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{in: `]`},
+		{in: `,`},
+		{in: `[}`},
+		{in: `{"a": [}`},
+		{in: `[1`, err: io.ErrUnexpectedEOF},
+		{in: `{"a": "b`, err: io.ErrUnexpectedEOF},
+		{in: `[tru]`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			sc := NewScanner(&SmallReader{r: strings.NewReader(tc.in)})
+			_, _, err := sc.NextValue()
+			switch {
+			case err == nil:
+				t.Fatalf("expected err, got: %v", err)
+			case tc.err != nil && err != tc.err:
+				t.Fatalf("expected: %v, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestScannerSkipValue(t *testing.T) {
+	// skip the value of "skip" in each object, keeping the others.
+	in := `[{"skip": {"a": [1, [2, {"b": []}]], "c": "}"}, "keep": 1}, {"keep": 2, "skip": [[[[]]]]}, {"skip": null}]`
+	sc := NewScanner(&SmallReader{r: strings.NewReader(in)})
+	var kept []string
+	for {
+		tok := sc.Next()
+		if len(tok) < 1 {
+			break
+		}
+		switch string(tok) {
+		case `"skip"`:
+			sc.Next() // :
+			check(t, sc.SkipValue())
+		case `"keep"`:
+			sc.Next() // :
+			kept = append(kept, string(sc.Next()))
+		}
+	}
+	if err := sc.Error(); err != io.EOF {
+		t.Fatal(err)
+	}
+	if strings.Join(kept, " ") != "1 2" {
+		t.Fatalf("expected: %q, got: %q", "1 2", kept)
+	}
+
+	// the scanner is positioned at the token after the value.
+	sc = NewScanner(strings.NewReader(`{"a": {"b": 1}} true`))
+	check(t, sc.SkipValue())
+	if tok := string(sc.Next()); tok != `true` {
+		t.Fatalf("expected: %q, got: %q", `true`, tok)
+	}
+	if err := sc.SkipValue(); err != io.EOF {
+		t.Fatalf("expected: %v, got: %v", io.EOF, err)
+	}
+
+	for _, in := range []string{`{"a": [1, 2`, `[`, `{"a": "b`} {
+		if err := NewScanner(strings.NewReader(in)).SkipValue(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("%q: expected: %v, got: %v", in, io.ErrUnexpectedEOF, err)
+		}
+	}
+}
+
+func TestScannerRawValue(t *testing.T) {
+	in := `{"a": [1, { "b" : "c\u0064" }], "e": 2.5e3} "f" [ ]`
+	sc := NewScanner(&SmallReader{r: strings.NewReader(in)})
+	var raws [][]byte
+	for {
+		raw, err := sc.RawValue()
+		if err == io.EOF {
+			break
+		}
+		check(t, err)
+		raws = append(raws, raw)
+	}
+	// each value remains valid after further scanning.
+	want := []string{`{"a": [1, { "b" : "c\u0064" }], "e": 2.5e3}`, `"f"`, `[ ]`}
+	if len(raws) != len(want) {
+		t.Fatalf("expected: %d values, got: %d", len(want), len(raws))
+	}
+	for i := range want {
+		if string(raws[i]) != want[i] {
+			t.Fatalf("expected: %q, got: %q", want[i], raws[i])
+		}
+	}
+
+	// the value of a member.
+	sc = NewScanner(strings.NewReader(`{"skip": 1, "raw": {"x": [true]}, "after": null}`))
+	for string(sc.Next()) != `"raw"` {
+	}
+	sc.Next() // :
+	raw, err := sc.RawValue()
+	check(t, err)
+	if string(raw) != `{"x": [true]}` {
+		t.Fatalf("expected: %q, got: %q", `{"x": [true]}`, raw)
+	}
+	if tok := string(sc.Next()); tok != `,` {
+		t.Fatalf("expected: %q, got: %q", `,`, tok)
+	}
+
+	for _, in := range []string{`{"a": [1, 2`, `[`, `"abc`} {
+		if raw, err := NewScanner(strings.NewReader(in)).RawValue(); raw != nil || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("%q: expected: %v, got: %q, %v", in, io.ErrUnexpectedEOF, raw, err)
+		}
+	}
+	if _, err := NewScanner(strings.NewReader(`[1}`)).RawValue(); err == nil {
+		t.Fatalf("expected err, got: %v", err)
+	}
+}